@@ -12,6 +12,7 @@ import (
 	"gotasksys/internal/api/handler"    // 导入所有的API处理器 (Handler)
 	"gotasksys/internal/api/middleware" // 导入所有的中间件 (Middleware)
 	"gotasksys/internal/config"         // 导入配置加载和数据库初始化模块
+	"gotasksys/internal/service"        // 导入业务服务层 (用于启动定时调度器)
 
 	"github.com/gin-contrib/cors" // 导入CORS中间件库
 	"github.com/gin-gonic/gin"    // 导入Gin框架库
@@ -26,6 +27,9 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 	config.InitDB(cfg)
+	service.InitPersonnelStatusCache()        // 相关数据变化时清空人员看板缓存
+	service.InitScheduler()                   // 启动计划任务与系统级定时作业
+	service.StartJobWorkers(cfg.Jobs.Workers) // 启动后台作业队列的 worker
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{"http://localhost:5173"},
@@ -51,6 +55,21 @@ func main() {
 			authRequired.GET("/task-types", handler.ListTaskTypes)
 			authRequired.GET("/dashboard/summary", handler.GetDashboardSummary)
 			authRequired.GET("/personnel/status", handler.GetPersonnelStatus)
+			authRequired.GET("/personnel/forecast", handler.GetCapacityForecast)       // 未来N周逐日负载预测
+			authRequired.GET("/personnel/teams", handler.GetTeamWorkload)              // 按团队汇总负载与产能
+			authRequired.GET("/performance/users/:id", handler.GetUserPerformance)     // 按时间窗口和权重统计的个人绩效
+			authRequired.GET("/performance/teams", handler.GetTeamPerformance)         // 团队绩效
+			authRequired.GET("/performance/reviewers", handler.GetReviewerCalibration) // 评价人打分习惯校准分析 (仅经理)
+			authRequired.GET("/analytics/flow", handler.GetFlowAnalytics)              // 前置时间、周期时间、吞吐量与累积流图
+			authRequired.GET("/reports/performance", handler.GetPerformanceReport)     // 导出绩效报告 (CSV/XLSX/可打印HTML)
+
+			// === 季度末自动生成的绩效报告 (仅经理) ===
+			reportRoutes := authRequired.Group("/reports")
+			reportRoutes.Use(middleware.ManagerAuthMiddleware())
+			{
+				reportRoutes.GET("", handler.ListGeneratedReports)                 // 我的已生成报告
+				reportRoutes.GET("/:id/download", handler.DownloadGeneratedReport) // 下载报告
+			}
 
 			// === 新增：用户个人请假管理路由 ===
 			leaveRoutes := authRequired.Group("/profile/leaves")
@@ -60,6 +79,20 @@ func main() {
 				leaveRoutes.POST("/:id/delete", handler.DeleteLeaveHandler) // 删除请假
 			}
 
+			// 技能：技能库对所有人可见，个人技能为自评
+			authRequired.GET("/skills", handler.ListSkills)
+			authRequired.GET("/profile/skills", handler.ListMySkills)
+			authRequired.POST("/profile/skills", handler.SetMySkill)
+			authRequired.POST("/profile/skills/:skill_id/delete", handler.DeleteMySkill)
+
+			// 成员技能核实 (仅Manager可访问)
+			memberRoutes := authRequired.Group("/members")
+			memberRoutes.Use(middleware.ManagerAuthMiddleware())
+			{
+				memberRoutes.GET("/:id/skills", handler.ListMemberSkills)
+				memberRoutes.POST("/:id/skills/:skill_id/verify", handler.VerifyMemberSkill)
+			}
+
 			// 任务管理路由(需要用户认证)
 			authRequired.POST("/tasks", handler.CreateTask)
 			authRequired.GET("/tasks", handler.ListTasks)
@@ -69,6 +102,7 @@ func main() {
 
 			// 任务工作流
 			authRequired.POST("/tasks/:id/approve", handler.ApproveTask)
+			authRequired.GET("/tasks/:id/approvals", handler.ListTaskApprovals) // 多级审批历史
 			authRequired.POST("/tasks/:id/reject", handler.RejectTask)
 			authRequired.POST("/tasks/:id/resubmit", handler.ResubmitTask)
 			authRequired.POST("/tasks/:id/claim", handler.ClaimTask)
@@ -77,15 +111,29 @@ func main() {
 
 			// 任务转交
 			authRequired.POST("/tasks/:id/transfer", handler.InitiateTransfer)
+			authRequired.GET("/transfers/incoming", handler.ListIncomingTransfers) // 我收到的转交 (收件箱)
+			authRequired.GET("/transfers/outgoing", handler.ListOutgoingTransfers) // 我发起的转交 (发件箱)
 			authRequired.POST("/transfers/:transfer_id/accept", handler.AcceptTransfer)
 			authRequired.POST("/transfers/:transfer_id/reject", handler.RejectTransfer)
 			authRequired.POST("/transfers/:transfer_id/cancel", handler.CancelTransfer)
 
+			// 转交审批 (跨团队或接收人超载时需要经理审批，仅Manager可访问)
+			transferApprovalRoutes := authRequired.Group("/transfers")
+			transferApprovalRoutes.Use(middleware.ManagerAuthMiddleware())
+			{
+				transferApprovalRoutes.GET("/awaiting-approval", handler.ListTransfersAwaitingApproval)
+				transferApprovalRoutes.POST("/:transfer_id/approve", handler.ApproveTransferByManager)
+				transferApprovalRoutes.POST("/:transfer_id/deny", handler.DenyTransferByManager)
+			}
+
 			// 子任务管理路由
 			authRequired.POST("/tasks/:id/subtasks", handler.CreateSubtask)
 
 			// 管理员指派任务
 			authRequired.POST("/tasks/:id/assign", handler.AssignTask)
+			authRequired.GET("/tasks/:id/recommendations", handler.GetTaskRecommendations) // 推荐执行者
+			authRequired.GET("/tasks/:id/skills", handler.GetTaskSkills)                   // 生效的技能要求
+			authRequired.POST("/tasks/:id/skills", handler.SetTaskSkills)                  // 设置任务自身的技能要求
 			// 用户获取可用头像列表的路由
 			authRequired.GET("/system-avatars", handler.ListAvailableAvatars)
 
@@ -99,6 +147,21 @@ func main() {
 				periodicRoutes.POST("/:id/update", handler.UpdatePeriodicTask)
 				periodicRoutes.POST("/:id/delete", handler.DeletePeriodicTask)
 				periodicRoutes.POST("/:id/toggle", handler.TogglePeriodicTask)
+				periodicRoutes.GET("/:id/runs", handler.ListPeriodicTaskRuns)    // 触发记录
+				periodicRoutes.POST("/preview", handler.PreviewPeriodicSchedule) // 预览未保存的规则
+				periodicRoutes.GET("/:id/preview", handler.PreviewPeriodicTask)  // 预览触发时间
+				periodicRoutes.GET("/:id/dry-run", handler.DryRunPeriodicTask)   // 预演将创建的任务
+				periodicRoutes.POST("/:id/run", handler.RunPeriodicTaskNow)      // 立即触发一次
+				periodicRoutes.POST("/:id/pause", handler.PausePeriodicTask)     // 暂停到指定时间
+				periodicRoutes.POST("/:id/resume", handler.ResumePeriodicTask)   // 恢复
+			}
+
+			// 审核队列路由 (仅Manager可访问)
+			reviewRoutes := authRequired.Group("/review-queue")
+			reviewRoutes.Use(middleware.ManagerAuthMiddleware())
+			{
+				reviewRoutes.GET("", handler.GetReviewQueue)         // 待审批/待评价任务，按等待时长排序
+				reviewRoutes.GET("/stats", handler.GetReviewerStats) // 审核人时效统计
 			}
 		}
 
@@ -118,6 +181,40 @@ func main() {
 			adminRoutes.POST("/task-types", handler.CreateTaskType)
 			adminRoutes.POST("/task-types/:id/update", handler.UpdateTaskType)
 			adminRoutes.POST("/task-types/:id/delete", handler.DeleteTaskType)
+			adminRoutes.GET("/task-types/:id/skills", handler.ListTaskTypeSkills)
+			adminRoutes.POST("/task-types/:id/skills", handler.SetTaskTypeSkills)
+			// 技能标签库
+			adminRoutes.GET("/skills", handler.ListSkills)
+			adminRoutes.POST("/skills", handler.CreateSkill)
+			adminRoutes.POST("/skills/:id/update", handler.UpdateSkill)
+			adminRoutes.POST("/skills/:id/delete", handler.DeleteSkill)
+			// 多级审批策略管理
+			adminRoutes.GET("/approval-policies", handler.ListApprovalPolicies)
+			adminRoutes.POST("/approval-policies", handler.CreateApprovalPolicy)
+			adminRoutes.POST("/approval-policies/:id/update", handler.UpdateApprovalPolicy)
+			adminRoutes.POST("/approval-policies/:id/delete", handler.DeleteApprovalPolicy)
+			// 审核SLA策略管理
+			adminRoutes.GET("/review-slas", handler.ListReviewSLAPolicies)
+			adminRoutes.POST("/review-slas", handler.CreateReviewSLAPolicy)
+			adminRoutes.POST("/review-slas/:id/update", handler.UpdateReviewSLAPolicy)
+			adminRoutes.POST("/review-slas/:id/delete", handler.DeleteReviewSLAPolicy)
+			// 系统配置与节假日
+			adminRoutes.GET("/configs", handler.ListSystemConfigs)
+			adminRoutes.POST("/configs/:key/update", handler.UpdateSystemConfig)
+			adminRoutes.GET("/holidays", handler.ListHolidays)
+			adminRoutes.POST("/holidays", handler.CreateHoliday)
+			adminRoutes.POST("/holidays/:id/delete", handler.DeleteHoliday)
+			// 按团队/角色覆盖的状态灯阈值
+			adminRoutes.GET("/status-light-thresholds", handler.ListStatusLightThresholds)
+			adminRoutes.POST("/status-light-thresholds", handler.CreateStatusLightThreshold)
+			adminRoutes.POST("/status-light-thresholds/:id/update", handler.UpdateStatusLightThreshold)
+			adminRoutes.POST("/status-light-thresholds/:id/delete", handler.DeleteStatusLightThreshold)
+			// 后台作业队列
+			adminRoutes.GET("/jobs", handler.ListJobs)
+			adminRoutes.GET("/jobs/:id", handler.GetJob)
+			adminRoutes.POST("/jobs/:id/retry", handler.RetryJob)
+			// 重新计算评价校准分
+			adminRoutes.POST("/calibration/recompute", handler.RecalibrateEvaluations)
 			// 管理员头像库管理路由组
 			avatarRoutes := adminRoutes.Group("/system-avatars")
 			{
@@ -172,7 +172,8 @@ func ListTaskTypes(c *gin.Context) {
 
 // 2、创建任务类型
 type CreateTaskTypeInput struct {
-	Name string `json:"name" binding:"required"`
+	Name      string  `json:"name" binding:"required"`
+	KeyPrefix *string `json:"key_prefix"` // 任务编号前缀，如 'OPS'
 }
 
 func CreateTaskType(c *gin.Context) {
@@ -182,9 +183,9 @@ func CreateTaskType(c *gin.Context) {
 		return
 	}
 
-	createdType, err := service.CreateTaskTypeService(input.Name)
+	createdType, err := service.CreateTaskTypeService(input.Name, input.KeyPrefix)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task type"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create task type", "details": err.Error()})
 		return
 	}
 	c.JSON(http.StatusCreated, createdType)
@@ -192,8 +193,9 @@ func CreateTaskType(c *gin.Context) {
 
 // 3、更新任务类型
 type UpdateTaskTypeInput struct {
-	Name      string `json:"name" binding:"required"`
-	IsEnabled bool   `json:"is_enabled"`
+	Name      string  `json:"name" binding:"required"`
+	KeyPrefix *string `json:"key_prefix"`
+	IsEnabled bool    `json:"is_enabled"`
 }
 
 // 4、修改任务类型
@@ -209,8 +211,8 @@ func UpdateTaskType(c *gin.Context) {
 		return
 	}
 
-	if err := service.UpdateTaskTypeService(typeID, input.Name, input.IsEnabled); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task type"})
+	if err := service.UpdateTaskTypeService(typeID, input.Name, input.KeyPrefix, input.IsEnabled); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update task type", "details": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Task type updated successfully"})
@@ -0,0 +1,43 @@
+// internal/api/handler/analytics_handler.go
+package handler
+
+import (
+	"gotasksys/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetFlowAnalytics 任务流动分析：各阶段时长分布、每周吞吐量和累积流图数据 (仅经理)
+// ?period=2026-Q3|2026-07|2026 或 ?start=&end=，?task_type_id=&priority=&team= 用于筛选
+func GetFlowAnalytics(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	query := service.FlowAnalyticsQuery{
+		Period:   c.Query("period"),
+		Start:    c.Query("start"),
+		End:      c.Query("end"),
+		Priority: c.Query("priority"),
+		Team:     c.Query("team"),
+	}
+	if raw := c.Query("task_type_id"); raw != "" {
+		taskTypeID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task type ID"})
+			return
+		}
+		query.TaskTypeID = &taskTypeID
+	}
+
+	analytics, err := service.GetFlowAnalyticsService(query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, analytics)
+}
@@ -0,0 +1,90 @@
+// internal/api/handler/approval_policy_handler.go
+package handler
+
+import (
+	"gotasksys/internal/model"
+	"gotasksys/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ApprovalPolicyInput 定义了创建/更新多级审批策略时的参数
+type ApprovalPolicyInput struct {
+	Name              string     `json:"name" binding:"required"`
+	MinEffort         *int       `json:"min_effort"`
+	TaskTypeID        *uuid.UUID `json:"task_type_id"`
+	RequiredApprovals int        `json:"required_approvals" binding:"required,gte=1"`
+	RequiredRole      string     `json:"required_role"`
+	IsActive          *bool      `json:"is_active"`
+}
+
+// buildApprovalPolicy 将输入转换为模型，未指定 is_active 时默认启用
+func buildApprovalPolicy(input ApprovalPolicyInput) model.ApprovalPolicy {
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+	return model.ApprovalPolicy{
+		Name: input.Name, MinEffort: input.MinEffort, TaskTypeID: input.TaskTypeID,
+		RequiredApprovals: input.RequiredApprovals, RequiredRole: input.RequiredRole, IsActive: isActive,
+	}
+}
+
+func ListApprovalPolicies(c *gin.Context) {
+	policies, err := service.ListApprovalPoliciesService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list approval policies"})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+func CreateApprovalPolicy(c *gin.Context) {
+	var input ApprovalPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := service.CreateApprovalPolicyService(buildApprovalPolicy(input))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func UpdateApprovalPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+	var input ApprovalPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := service.UpdateApprovalPolicyService(id, buildApprovalPolicy(input))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func DeleteApprovalPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+	if err := service.DeleteApprovalPolicyService(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete approval policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Approval policy deleted successfully"})
+}
@@ -2,6 +2,7 @@
 package handler
 
 import (
+	"gotasksys/internal/model"
 	"gotasksys/internal/service"
 	"net/http"
 	"time"
@@ -12,6 +13,16 @@ import (
 
 // --- 系统配置相关 Handler ---
 
+// ListSystemConfigs 获取所有系统配置项
+func ListSystemConfigs(c *gin.Context) {
+	configs, err := service.ListSystemConfigsService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list system configs"})
+		return
+	}
+	c.JSON(http.StatusOK, configs)
+}
+
 type UpdateConfigInput struct {
 	Value string `json:"value" binding:"required"`
 }
@@ -84,3 +95,75 @@ func DeleteHoliday(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Holiday deleted successfully"})
 }
+
+// --- 状态灯阈值覆盖 (管理员) ---
+
+type StatusLightThresholdInput struct {
+	ScopeType  string  `json:"scope_type" binding:"required,oneof=team role"`
+	ScopeValue string  `json:"scope_value" binding:"required"`
+	Mode       string  `json:"mode" binding:"omitempty,oneof=percentage hours"`
+	NormalMax  float64 `json:"normal_max" binding:"required,gt=0"`
+	BusyMax    float64 `json:"busy_max" binding:"required,gt=0"`
+}
+
+func (input StatusLightThresholdInput) toModel() model.StatusLightThreshold {
+	return model.StatusLightThreshold{
+		ScopeType: input.ScopeType, ScopeValue: input.ScopeValue, Mode: input.Mode,
+		NormalMax: input.NormalMax, BusyMax: input.BusyMax,
+	}
+}
+
+func ListStatusLightThresholds(c *gin.Context) {
+	thresholds, err := service.ListStatusLightThresholdsService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list status light thresholds"})
+		return
+	}
+	c.JSON(http.StatusOK, thresholds)
+}
+
+func CreateStatusLightThreshold(c *gin.Context) {
+	var input StatusLightThresholdInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, err := service.CreateStatusLightThresholdService(input.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func UpdateStatusLightThreshold(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold ID"})
+		return
+	}
+	var input StatusLightThresholdInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := service.UpdateStatusLightThresholdService(id, input.toModel())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func DeleteStatusLightThreshold(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid threshold ID"})
+		return
+	}
+	if err := service.DeleteStatusLightThresholdService(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete status light threshold"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Status light threshold deleted successfully"})
+}
@@ -0,0 +1,54 @@
+// internal/api/handler/job_handler.go
+package handler
+
+import (
+	"gotasksys/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListJobs 查询后台作业队列，可通过 ?status=dead&job_type=xxx&limit=50 过滤
+func ListJobs(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	jobs, err := service.ListJobsService(c.Query("status"), c.Query("job_type"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list jobs"})
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJob 查询单个后台作业的详情 (包括最近一次错误)
+func GetJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := service.GetJobService(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJob 手动重试一个已转入死信的作业
+func RetryJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return
+	}
+
+	job, err := service.RetryJobService(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
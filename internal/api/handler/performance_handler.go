@@ -0,0 +1,97 @@
+// internal/api/handler/performance_handler.go
+package handler
+
+import (
+	"gotasksys/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// bindPerformanceQuery 读取绩效查询参数：?window=month|quarter|rolling&days=90&weighting=effort&min_samples=3&score_basis=raw|normalized
+func bindPerformanceQuery(c *gin.Context) service.PerformanceQuery {
+	days, _ := strconv.Atoi(c.Query("days"))
+	minSamples, _ := strconv.Atoi(c.Query("min_samples"))
+	return service.PerformanceQuery{
+		Window:     c.Query("window"),
+		Days:       days,
+		Weighting:  c.Query("weighting"),
+		MinSamples: minSamples,
+		ScoreBasis: c.Query("score_basis"),
+	}
+}
+
+// GetUserPerformance 获取单个用户的绩效 (经理可查看所有人，其他人只能查看自己)
+func GetUserPerformance(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" && c.GetString("user_id") != userID.String() {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	performance, err := service.GetUserPerformanceService(userID, bindPerformanceQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, performance)
+}
+
+// GetTeamPerformance 获取各团队的绩效，可通过 ?team=xxx 只查看某个团队
+func GetTeamPerformance(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	teams, err := service.GetTeamPerformanceService(c.Query("team"), bindPerformanceQuery(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
+
+// GetReviewerCalibration 评价人校准分析：每位评价人的打分均值、方差、分布及与其他评价人的对比
+// ?days=N 指定统计最近多少天的评价 (0 表示全部)，不指定时使用系统配置
+func GetReviewerCalibration(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	days := -1
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be a non-negative integer"})
+			return
+		}
+		days = parsed
+	}
+
+	report, err := service.GetReviewerCalibrationService(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute reviewer calibration"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// RecalibrateEvaluations 手动触发重新计算所有评价的校准分 (后台作业执行)
+func RecalibrateEvaluations(c *gin.Context) {
+	if err := service.RequestRecalibrationService(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Recalibration has been scheduled"})
+}
@@ -5,6 +5,7 @@ import (
 	"gotasksys/internal/model"
 	"gotasksys/internal/service"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,15 +14,25 @@ import (
 
 // PeriodicTaskInput (最终版)
 type PeriodicTaskInput struct {
-	Title             string     `json:"title" binding:"required"`
-	Description       string     `json:"description"`
-	CronExpression    string     `json:"cron_expression" binding:"required"`
-	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id"`
-	DefaultEffort     int        `json:"default_effort" binding:"required"`
-	DefaultPriority   string     `json:"default_priority" binding:"required"`
-	DefaultTaskTypeID *uuid.UUID `json:"default_task_type_id"`
-	StartDate         *time.Time `json:"start_date"` // 新增
-	EndDate           *time.Time `json:"end_date"`   // 新增
+	Title             string      `json:"title" binding:"required"`
+	Description       string      `json:"description"`
+	CronExpression    string      `json:"cron_expression"` // 与 rrule 二选一
+	RRule             string      `json:"rrule"`           // RFC 5545 RRULE，如 'FREQ=MONTHLY;BYDAY=2TU;BYHOUR=9'
+	TimeZone          string      `json:"time_zone"`       // IANA 时区，如 'Asia/Shanghai'
+	DefaultAssigneeID *uuid.UUID  `json:"default_assignee_id"`
+	DefaultEffort     int         `json:"default_effort" binding:"required"`
+	DefaultPriority   string      `json:"default_priority" binding:"required"`
+	DefaultTaskTypeID *uuid.UUID  `json:"default_task_type_id"`
+	StartDate         *time.Time  `json:"start_date"`         // 新增
+	EndDate           *time.Time  `json:"end_date"`           // 新增
+	HolidayPolicy     string      `json:"holiday_policy"`     // 'none', 'skip', 'next_working_day', 'previous_working_day'
+	LeaveFallback     string      `json:"leave_fallback"`     // 'none', 'pool', 'backup'
+	BackupAssigneeID  *uuid.UUID  `json:"backup_assignee_id"` // 默认负责人请假时的备岗人员
+	DueOffsetType     string      `json:"due_offset_type"`    // 'none', 'working_days', 'end_of_week', 'end_of_month'
+	DueOffsetValue    int         `json:"due_offset_value"`   // 'working_days' 时为工作日天数
+	RotationStrategy  string      `json:"rotation_strategy"`  // 'none', 'round_robin', 'skip_on_leave', 'least_loaded'
+	RotationUserIDs   []uuid.UUID `json:"rotation_user_ids"`  // 轮值池成员，按轮换顺序排列
+	OverlapPolicy     string      `json:"overlap_policy"`     // 'always', 'skip_if_open', 'roll_forward'
 }
 
 func ListPeriodicTasks(c *gin.Context) {
@@ -44,10 +55,16 @@ func CreatePeriodicTask(c *gin.Context) {
 
 	pt := model.PeriodicTask{
 		Title: input.Title, Description: input.Description, CronExpression: input.CronExpression,
+		RRule: input.RRule, TimeZone: input.TimeZone,
 		DefaultAssigneeID: input.DefaultAssigneeID, DefaultEffort: input.DefaultEffort,
 		DefaultPriority: input.DefaultPriority, DefaultTaskTypeID: input.DefaultTaskTypeID,
-		StartDate: input.StartDate, // 传递新字段
-		EndDate:   input.EndDate,   // 传递新字段
+		StartDate:     input.StartDate, // 传递新字段
+		EndDate:       input.EndDate,   // 传递新字段
+		HolidayPolicy: input.HolidayPolicy, LeaveFallback: input.LeaveFallback,
+		BackupAssigneeID: input.BackupAssigneeID,
+		DueOffsetType:    input.DueOffsetType, DueOffsetValue: input.DueOffsetValue,
+		RotationStrategy: input.RotationStrategy, RotationUserIDs: input.RotationUserIDs,
+		OverlapPolicy: input.OverlapPolicy,
 	}
 
 	createdTask, err := service.CreatePeriodicTaskService(pt, creatorID)
@@ -69,10 +86,16 @@ func UpdatePeriodicTask(c *gin.Context) {
 
 	pt := model.PeriodicTask{
 		Title: input.Title, Description: input.Description, CronExpression: input.CronExpression,
+		RRule: input.RRule, TimeZone: input.TimeZone,
 		DefaultAssigneeID: input.DefaultAssigneeID, DefaultEffort: input.DefaultEffort,
 		DefaultPriority: input.DefaultPriority, DefaultTaskTypeID: input.DefaultTaskTypeID,
-		StartDate: input.StartDate, // 传递新字段
-		EndDate:   input.EndDate,   // 传递新字段
+		StartDate:     input.StartDate, // 传递新字段
+		EndDate:       input.EndDate,   // 传递新字段
+		HolidayPolicy: input.HolidayPolicy, LeaveFallback: input.LeaveFallback,
+		BackupAssigneeID: input.BackupAssigneeID,
+		DueOffsetType:    input.DueOffsetType, DueOffsetValue: input.DueOffsetValue,
+		RotationStrategy: input.RotationStrategy, RotationUserIDs: input.RotationUserIDs,
+		OverlapPolicy: input.OverlapPolicy,
 	}
 
 	updatedTask, err := service.UpdatePeriodicTaskService(id, pt)
@@ -108,3 +131,135 @@ func TogglePeriodicTask(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Periodic task status updated successfully"})
 }
+
+// ListPeriodicTaskRuns 获取一个计划任务最近的触发记录，可通过 ?limit=50 指定条数
+func ListPeriodicTaskRuns(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periodic task ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	runs, err := service.ListPeriodicTaskRunsService(id, limit)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// PreviewPeriodicSchedule 预览一条尚未保存的规则，可通过 ?count=10 指定预览的触发次数
+func PreviewPeriodicSchedule(c *gin.Context) {
+	var input struct {
+		CronExpression string     `json:"cron_expression"`
+		RRule          string     `json:"rrule"`
+		TimeZone       string     `json:"time_zone"`
+		StartDate      *time.Time `json:"start_date"`
+		EndDate        *time.Time `json:"end_date"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	count, _ := strconv.Atoi(c.DefaultQuery("count", "10"))
+
+	pt := model.PeriodicTask{
+		CronExpression: input.CronExpression, RRule: input.RRule, TimeZone: input.TimeZone,
+		StartDate: input.StartDate, EndDate: input.EndDate,
+	}
+	preview, err := service.PreviewPeriodicScheduleService(pt, count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// PreviewPeriodicTask 预览一条已保存规则接下来的触发时间
+func PreviewPeriodicTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periodic task ID"})
+		return
+	}
+	count, _ := strconv.Atoi(c.DefaultQuery("count", "10"))
+
+	preview, err := service.PreviewPeriodicTaskService(id, count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, preview)
+}
+
+// DryRunPeriodicTask 展示规则下一次触发时将创建的任务，不会写入数据库
+func DryRunPeriodicTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periodic task ID"})
+		return
+	}
+
+	result, err := service.DryRunPeriodicTaskService(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RunPeriodicTaskNow 立即触发一次计划任务 (例如临时加做一次盘点)
+func RunPeriodicTaskNow(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periodic task ID"})
+		return
+	}
+
+	run, err := service.RunPeriodicTaskNowService(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// PausePeriodicTask 暂停计划任务到指定时间，到期后自动恢复
+func PausePeriodicTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periodic task ID"})
+		return
+	}
+	var input struct {
+		Until time.Time `json:"until" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pt, err := service.PausePeriodicTaskService(id, input.Until)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pt)
+}
+
+// ResumePeriodicTask 立即恢复一个已暂停的计划任务
+func ResumePeriodicTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid periodic task ID"})
+		return
+	}
+
+	pt, err := service.ResumePeriodicTaskService(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, pt)
+}
@@ -4,10 +4,12 @@ package handler
 import (
 	"gotasksys/internal/service"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// GetPersonnelStatus 获取人员看板数据，可通过 ?team=xxx 只查看某个团队的成员
 func GetPersonnelStatus(c *gin.Context) {
 	userRole, _ := c.Get("user_role")
 	if userRole != "manager" && userRole != "system_admin" {
@@ -15,7 +17,7 @@ func GetPersonnelStatus(c *gin.Context) {
 		return
 	}
 
-	statuses, err := service.GetPersonnelStatusService()
+	statuses, err := service.GetPersonnelStatusService(c.Query("team"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get personnel status"})
 		return
@@ -23,3 +25,36 @@ func GetPersonnelStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, statuses)
 }
+
+// GetCapacityForecast 获取未来 N 周每个成员的逐日负载预测，可通过 ?weeks=4 指定预测周数
+func GetCapacityForecast(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+	weeks, _ := strconv.Atoi(c.DefaultQuery("weeks", "4"))
+
+	forecast, err := service.GetCapacityForecastService(weeks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get capacity forecast"})
+		return
+	}
+	c.JSON(http.StatusOK, forecast)
+}
+
+// GetTeamWorkload 按团队汇总产能、负载、超期任务、任务池需求和绩效
+func GetTeamWorkload(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	teams, err := service.GetTeamWorkloadService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get team workload"})
+		return
+	}
+	c.JSON(http.StatusOK, teams)
+}
@@ -0,0 +1,99 @@
+// internal/api/handler/report_handler.go
+package handler
+
+import (
+	"gotasksys/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetPerformanceReport 生成并下载绩效报告
+// ?user_id=xxx 或 ?team=xxx (都不指定时为所有成员)，?period=2026-Q3|2026-07|2026 或 ?start=&end=，?format=csv|xlsx|html，?score_basis=raw|normalized
+// 经理可生成任何范围的报告，其他人只能生成自己的个人报告
+func GetPerformanceReport(c *gin.Context) {
+	req := service.ReportRequest{
+		Team:       c.Query("team"),
+		Period:     c.Query("period"),
+		Start:      c.Query("start"),
+		End:        c.Query("end"),
+		ScoreBasis: c.Query("score_basis"),
+	}
+	if raw := c.Query("user_id"); raw != "" {
+		userID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		req.UserID = &userID
+	}
+
+	userRole, _ := c.Get("user_role")
+	isManager := userRole == "manager" || userRole == "system_admin"
+	if !isManager && (req.UserID == nil || req.UserID.String() != c.GetString("user_id")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "html")
+	if service.ReportContentType(format) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, must be one of: csv, xlsx, html"})
+		return
+	}
+
+	packet, err := service.BuildPerformanceReportService(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	content, contentType, fileName, err := service.RenderPerformanceReport(packet, format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+		return
+	}
+	writeReportFile(c, content, contentType, fileName, format)
+}
+
+// ListGeneratedReports 获取季度末为当前经理自动生成的报告
+func ListGeneratedReports(c *gin.Context) {
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+	reports, err := service.ListGeneratedReportsService(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reports"})
+		return
+	}
+	c.JSON(http.StatusOK, reports)
+}
+
+// DownloadGeneratedReport 下载一份已生成的报告
+func DownloadGeneratedReport(c *gin.Context) {
+	reportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+	userRole, _ := c.Get("user_role")
+
+	report, err := service.GetGeneratedReportService(reportID, userID, userRole.(string))
+	if err != nil {
+		status := http.StatusNotFound
+		if err.Error() == "permission denied" {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	writeReportFile(c, report.Content, service.ReportContentType(report.Format), report.FileName, report.Format)
+}
+
+// writeReportFile HTML 报告直接在浏览器中打开以便打印，其他格式作为附件下载
+func writeReportFile(c *gin.Context, content []byte, contentType, fileName, format string) {
+	disposition := "attachment"
+	if format == "html" {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", disposition+`; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, contentType, content)
+}
@@ -0,0 +1,107 @@
+// internal/api/handler/review_handler.go
+package handler
+
+import (
+	"gotasksys/internal/model"
+	"gotasksys/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetReviewQueue 获取经理的审核队列 (待审批 + 待评价)，按等待时长排序
+// 可通过 ?stage=approval 或 ?stage=evaluation 过滤
+func GetReviewQueue(c *gin.Context) {
+	items, err := service.GetReviewQueueService(c.Query("stage"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// GetReviewerStats 获取每个审核人的审批/评价时效统计，可通过 ?days=90 指定统计周期
+func GetReviewerStats(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "90"))
+
+	stats, err := service.GetReviewerStatsService(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reviewer statistics"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// --- 审核SLA策略管理 (管理员) ---
+
+type ReviewSLAPolicyInput struct {
+	Stage       string     `json:"stage" binding:"required,oneof=approval evaluation"`
+	Priority    *string    `json:"priority"`
+	TaskTypeID  *uuid.UUID `json:"task_type_id"`
+	TargetHours float64    `json:"target_hours" binding:"required,gt=0"`
+}
+
+func ListReviewSLAPolicies(c *gin.Context) {
+	policies, err := service.ListReviewSLAPoliciesService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list review SLA policies"})
+		return
+	}
+	c.JSON(http.StatusOK, policies)
+}
+
+func CreateReviewSLAPolicy(c *gin.Context) {
+	var input ReviewSLAPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := model.ReviewSLAPolicy{
+		Stage: input.Stage, Priority: input.Priority, TaskTypeID: input.TaskTypeID, TargetHours: input.TargetHours,
+	}
+	created, err := service.CreateReviewSLAPolicyService(policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func UpdateReviewSLAPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+	var input ReviewSLAPolicyInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := model.ReviewSLAPolicy{
+		Stage: input.Stage, Priority: input.Priority, TaskTypeID: input.TaskTypeID, TargetHours: input.TargetHours,
+	}
+	updated, err := service.UpdateReviewSLAPolicyService(id, policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func DeleteReviewSLAPolicy(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid policy ID"})
+		return
+	}
+	if err := service.DeleteReviewSLAPolicyService(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete review SLA policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Review SLA policy deleted successfully"})
+}
@@ -0,0 +1,248 @@
+// internal/api/handler/skill_handler.go
+package handler
+
+import (
+	"gotasksys/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// --- 技能标签库 ---
+
+type SkillInput struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// ListSkills 获取所有技能标签 (所有登录用户可见，用于填写个人技能)
+func ListSkills(c *gin.Context) {
+	skills, err := service.ListSkillsService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list skills"})
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+func CreateSkill(c *gin.Context) {
+	var input SkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	skill, err := service.CreateSkillService(input.Name, input.Description)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, skill)
+}
+
+func UpdateSkill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+	var input SkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	skill, err := service.UpdateSkillService(id, input.Name, input.Description)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, skill)
+}
+
+func DeleteSkill(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+	if err := service.DeleteSkillService(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete skill"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Skill deleted successfully"})
+}
+
+// --- 个人技能 (自评) ---
+
+type SkillLevelInput struct {
+	SkillID uuid.UUID `json:"skill_id"`
+	Level   int       `json:"level" binding:"required"`
+}
+
+// ListMySkills 获取当前用户的技能
+func ListMySkills(c *gin.Context) {
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+	skills, err := service.ListUserSkillsService(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list skills"})
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+// SetMySkill 新增或更新当前用户对一项技能的自评等级
+func SetMySkill(c *gin.Context) {
+	var input SkillLevelInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+	if err := service.SetMySkillService(userID, input.SkillID, input.Level); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Skill saved successfully"})
+}
+
+// DeleteMySkill 删除当前用户的一项技能
+func DeleteMySkill(c *gin.Context) {
+	skillID, err := uuid.Parse(c.Param("skill_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+	if err := service.DeleteMySkillService(userID, skillID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete skill"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Skill deleted successfully"})
+}
+
+// --- 成员技能核实 (经理) ---
+
+// ListMemberSkills 查看某个成员的技能
+func ListMemberSkills(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	skills, err := service.ListUserSkillsService(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list skills"})
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+type VerifySkillInput struct {
+	Level int `json:"level" binding:"required"`
+}
+
+// VerifyMemberSkill 经理核实成员某项技能的等级
+func VerifyMemberSkill(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	skillID, err := uuid.Parse(c.Param("skill_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid skill ID"})
+		return
+	}
+	var input VerifySkillInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	verifierID, _ := uuid.Parse(c.GetString("user_id"))
+	if err := service.VerifyUserSkillService(userID, skillID, input.Level, verifierID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Skill verified successfully"})
+}
+
+// --- 任务技能要求 ---
+
+type SkillRequirementsInput struct {
+	Skills []service.SkillRequirementInput `json:"skills"`
+}
+
+// ListTaskTypeSkills 获取任务类型的默认技能要求
+func ListTaskTypeSkills(c *gin.Context) {
+	typeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task type ID"})
+		return
+	}
+	skills, err := service.ListTaskTypeSkillsService(typeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list task type skills"})
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+// SetTaskTypeSkills 整体替换任务类型的默认技能要求
+func SetTaskTypeSkills(c *gin.Context) {
+	typeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task type ID"})
+		return
+	}
+	var input SkillRequirementsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	skills, err := service.SetTaskTypeSkillsService(typeID, input.Skills)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, skills)
+}
+
+// GetTaskSkills 获取任务实际生效的技能要求
+func GetTaskSkills(c *gin.Context) {
+	taskID, err := parseTaskID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	requirements, err := service.GetTaskSkillsService(uint(taskID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requirements)
+}
+
+// SetTaskSkills 设置单个任务的技能要求 (仅Manager)，传入空列表恢复为继承任务类型的要求
+func SetTaskSkills(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+	taskID, err := parseTaskID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	var input SkillRequirementsInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	requirements, err := service.SetTaskSkillsService(uint(taskID), input.Skills)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requirements)
+}
@@ -67,7 +67,7 @@ func ListTasks(c *gin.Context) {
 func GetTask(c *gin.Context) {
 	// 从URL路径中获取id参数, e.g., /tasks/123
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := parseTaskID(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -96,7 +96,7 @@ type UpdateTaskInput struct {
 // UpdateTask 更新一个已存在的任务 (最终权限版)
 func UpdateTask(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+	id, err := parseTaskID(idStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -144,7 +144,7 @@ func UpdateTask(c *gin.Context) {
 // DeleteTask 删除一个任务 (最终权限版)
 func DeleteTask(c *gin.Context) {
 	// 从URL解析任务ID
-	taskID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	taskID, err := parseTaskID(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -181,15 +181,21 @@ type ApproveTaskInput struct {
 	Priority         string             `json:"priority" binding:"required"`
 	TaskTypeID       string             `json:"task_type_id" binding:"required,uuid"`
 	DifficultyRating map[string]float64 `json:"difficulty_rating" binding:"required"` // 新增
+	Comment          string             `json:"comment"`                              // 审批意见
 }
 
 // ApproveTask 批准任务时接收并设定工时 (最终版)
 func ApproveTask(c *gin.Context) {
-	// ... (权限校验和参数解析部分不变) ...
 	userRole, _ := c.Get("user_role")
-	if userRole != "manager" && userRole != "system_admin" { /* ... */
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied. Only managers can approve tasks."})
+		return
+	}
+	taskID, err := parseTaskID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
 	}
-	taskID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	reviewerID, _ := uuid.Parse(c.GetString("user_id"))
 
 	var input ApproveTaskInput
@@ -199,12 +205,33 @@ func ApproveTask(c *gin.Context) {
 	}
 	taskTypeID, _ := uuid.Parse(input.TaskTypeID)
 
-	err := service.ApproveTaskService(uint(taskID), reviewerID, input.Effort, input.Priority, taskTypeID, input.DifficultyRating)
+	outcome, err := service.ApproveTaskService(uint(taskID), reviewerID, input.Effort, input.Priority, taskTypeID, input.DifficultyRating, input.Comment)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Task approved successfully."})
+	if !outcome.Approved {
+		// 多级审批尚未满足，任务继续等待其他审批人
+		c.JSON(http.StatusOK, gin.H{"message": "Approval recorded. Waiting for further approvals.", "approval": outcome})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Task approved successfully.", "approval": outcome})
+}
+
+// ListTaskApprovals 获取一个任务的审批历史 (每位审批人的决定、工时建议和难度评分)
+func ListTaskApprovals(c *gin.Context) {
+	taskID, err := parseTaskID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+
+	approvals, err := service.ListTaskApprovalsService(uint(taskID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task approvals"})
+		return
+	}
+	c.JSON(http.StatusOK, approvals)
 }
 
 // ClaimTask 允许用户从任务池中领取任务
@@ -218,7 +245,7 @@ func ClaimTask(c *gin.Context) {
 	// ---------------------------
 
 	taskIDStr := c.Param("id")
-	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	taskID, err := parseTaskID(taskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -227,7 +254,7 @@ func ClaimTask(c *gin.Context) {
 	assigneeIDStr, _ := c.Get("user_id")
 	assigneeID, _ := uuid.Parse(assigneeIDStr.(string))
 
-	err = service.ClaimTaskService(uint(taskID), assigneeID)
+	skillWarnings, err := service.ClaimTaskService(uint(taskID), assigneeID)
 	// ...后续错误处理逻辑保持不变...
 	if err != nil {
 		if err.Error() == "task is not available to be claimed" || err.Error() == "task has already been assigned" {
@@ -242,13 +269,17 @@ func ClaimTask(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Task claimed successfully."})
+	response := gin.H{"message": "Task claimed successfully."}
+	if len(skillWarnings) > 0 {
+		response["skill_warnings"] = skillWarnings // 技能不匹配的提示，不影响领取结果
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // CompleteTask 允许负责人完成任务并提交评价 (最终版)
 func CompleteTask(c *gin.Context) {
 	taskIDStr := c.Param("id")
-	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	taskID, err := parseTaskID(taskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -290,7 +321,7 @@ func EvaluateTask(c *gin.Context) {
 	// --- 不再在这里做简单的角色校验，将由Service层进行复杂的校验 ---
 
 	taskIDStr := c.Param("id")
-	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	taskID, err := parseTaskID(taskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -352,7 +383,7 @@ func RejectTask(c *gin.Context) {
 
 	// 2. 解析URL中的任务ID
 	taskIDStr := c.Param("id")
-	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	taskID, err := parseTaskID(taskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -393,7 +424,7 @@ func RejectTask(c *gin.Context) {
 func ResubmitTask(c *gin.Context) {
 	// 1. 解析URL中的任务ID
 	taskIDStr := c.Param("id")
-	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	taskID, err := parseTaskID(taskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -443,7 +474,7 @@ type CreateSubtaskInput struct {
 func CreateSubtask(c *gin.Context) {
 	// 1. 解析父任务ID (逻辑不变)
 	parentTaskIDStr := c.Param("id")
-	parentTaskID, err := strconv.ParseUint(parentTaskIDStr, 10, 64)
+	parentTaskID, err := parseTaskID(parentTaskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent task ID"})
 		return
@@ -508,7 +539,7 @@ func AssignTask(c *gin.Context) {
 	}
 
 	taskIDStr := c.Param("id")
-	taskID, err := strconv.ParseUint(taskIDStr, 10, 64)
+	taskID, err := parseTaskID(taskIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
 		return
@@ -523,11 +554,46 @@ func AssignTask(c *gin.Context) {
 	assigneeID, _ := uuid.Parse(input.AssigneeID)
 	managerID, _ := uuid.Parse(c.GetString("user_id"))
 
-	err = service.AssignTaskService(uint(taskID), assigneeID, managerID)
+	skillWarnings, err := service.AssignTaskService(uint(taskID), assigneeID, managerID)
 	if err != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Task assigned successfully."})
+	response := gin.H{"message": "Task assigned successfully."}
+	if len(skillWarnings) > 0 {
+		response["skill_warnings"] = skillWarnings // 技能不匹配的提示，不影响指派结果
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTaskRecommendations 为任务池中的任务推荐执行者，可通过 ?limit=5 指定返回人数
+func GetTaskRecommendations(c *gin.Context) {
+	userRole, _ := c.Get("user_role")
+	if userRole != "manager" && userRole != "system_admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Permission denied"})
+		return
+	}
+	taskID, err := parseTaskID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	recommendations, err := service.GetAssigneeRecommendationsService(uint(taskID), limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, recommendations)
+}
+
+// parseTaskID 解析URL中的任务标识，同时支持数字ID (如 128) 和任务编号 (如 OPS-128)
+func parseTaskID(ref string) (uint64, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return id, nil
+	}
+	id, err := service.ResolveTaskKeyService(ref)
+	return uint64(id), err
 }
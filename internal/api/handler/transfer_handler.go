@@ -2,9 +2,10 @@
 package handler
 
 import (
+	"errors"
 	"gotasksys/internal/service"
+	"io"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,10 +14,15 @@ import (
 type InitiateTransferInput struct {
 	NewAssigneeID string `json:"new_assignee_id" binding:"required,uuid"`
 	EffortSpent   int    `json:"effort_spent" binding:"gte=0"`
+	HandoffNotes  string `json:"handoff_notes" binding:"required"` // 交接说明，必填
 }
 
 func InitiateTransfer(c *gin.Context) {
-	taskID, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	taskID, err := parseTaskID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task ID"})
+		return
+	}
 	initiatorID, _ := uuid.Parse(c.GetString("user_id"))
 
 	var input InitiateTransferInput
@@ -26,13 +32,19 @@ func InitiateTransfer(c *gin.Context) {
 	}
 	newAssigneeID, _ := uuid.Parse(input.NewAssigneeID)
 
-	transfer, err := service.InitiateTransferService(uint(taskID), initiatorID, newAssigneeID, input.EffortSpent)
+	transfer, err := service.InitiateTransferService(uint(taskID), initiatorID, newAssigneeID, input.EffortSpent, input.HandoffNotes)
 	if err != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Transfer initiated successfully.", "transfer_id": transfer.ID})
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Transfer initiated successfully.",
+		"transfer_id":       transfer.ID,
+		"status":            transfer.Status,
+		"requires_approval": transfer.RequiresApproval,
+		"policy_reason":     transfer.PolicyReason,
+	})
 }
 
 func AcceptTransfer(c *gin.Context) {
@@ -81,3 +93,103 @@ func CancelTransfer(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Transfer cancelled successfully."})
 }
+
+// ListIncomingTransfers 获取当前用户收到的转交请求，可通过 ?status=pending 过滤
+func ListIncomingTransfers(c *gin.Context) {
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+
+	transfers, err := service.ListIncomingTransfersService(userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve incoming transfers"})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// ListOutgoingTransfers 获取当前用户发起的转交请求，可通过 ?status=pending 过滤
+func ListOutgoingTransfers(c *gin.Context) {
+	userID, _ := uuid.Parse(c.GetString("user_id"))
+
+	transfers, err := service.ListOutgoingTransfersService(userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve outgoing transfers"})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// --- 经理审批转交 ---
+
+type TransferApprovalInput struct {
+	Comment string `json:"comment"`
+}
+
+// ListTransfersAwaitingApproval 获取所有等待经理审批的转交请求
+func ListTransfersAwaitingApproval(c *gin.Context) {
+	transfers, err := service.ListTransfersAwaitingApprovalService()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfers awaiting approval"})
+		return
+	}
+	c.JSON(http.StatusOK, transfers)
+}
+
+// ApproveTransferByManager 经理批准一个跨团队或超载的转交请求
+func ApproveTransferByManager(c *gin.Context) {
+	transferID, err := uuid.Parse(c.Param("transfer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return
+	}
+	// 审批意见是可选的，允许不带请求体
+	var input TransferApprovalInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	managerID, _ := uuid.Parse(c.GetString("user_id"))
+
+	if err := service.ApproveTransferByManagerService(transferID, managerID, input.Comment); err != nil {
+		if err.Error() == "transfer request not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "permission denied: you cannot decide on a transfer you initiated" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer approved and forwarded to the recipient."})
+}
+
+// DenyTransferByManager 经理驳回一个跨团队或超载的转交请求
+func DenyTransferByManager(c *gin.Context) {
+	transferID, err := uuid.Parse(c.Param("transfer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return
+	}
+	// 审批意见是可选的，允许不带请求体
+	var input TransferApprovalInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	managerID, _ := uuid.Parse(c.GetString("user_id"))
+
+	if err := service.DenyTransferByManagerService(transferID, managerID, input.Comment); err != nil {
+		if err.Error() == "transfer request not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "permission denied: you cannot decide on a transfer you initiated" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Transfer denied."})
+}
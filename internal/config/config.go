@@ -25,6 +25,9 @@ type Config struct {
 		Secret          string `yaml:"secret"`
 		ExpirationHours int    `yaml:"expiration_hours"`
 	} `yaml:"jwt"`
+	Jobs struct {
+		Workers int `yaml:"workers"` // 后台作业 worker 数量，0 表示本实例不执行作业
+	} `yaml:"jobs"`
 }
 
 // LoadConfig 从 config.yaml 文件加载配置
@@ -0,0 +1,41 @@
+// internal/model/approval.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ApprovalPolicy 定义了多级审批策略
+// 任务工时 >= MinEffort 或任务类型 == TaskTypeID 时命中该策略 (两者都设置时需同时满足)
+type ApprovalPolicy struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name              string     `gorm:"type:varchar(255);not null" json:"name"`
+	MinEffort         *int       `json:"min_effort,omitempty"`
+	TaskTypeID        *uuid.UUID `json:"task_type_id,omitempty"`
+	RequiredApprovals int        `gorm:"not null;default:1" json:"required_approvals"`
+	RequiredRole      string     `gorm:"type:varchar(50)" json:"required_role,omitempty"`
+	IsActive          bool       `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// TaskApproval 记录了一位审批人对任务的一次审批决定
+type TaskApproval struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TaskID           uint           `gorm:"not null;index" json:"task_id"`
+	ApproverID       uuid.UUID      `gorm:"not null" json:"approver_id"`
+	ApproverRole     string         `gorm:"type:varchar(50);not null" json:"approver_role"`
+	Decision         string         `gorm:"type:varchar(50);not null" json:"decision"` // 'approved', 'rejected'
+	Effort           int            `json:"effort,omitempty"`
+	Priority         string         `gorm:"type:varchar(50)" json:"priority,omitempty"`
+	TaskTypeID       *uuid.UUID     `json:"task_type_id,omitempty"`
+	DifficultyRating datatypes.JSON `json:"difficulty_rating,omitempty"`
+	Comment          string         `gorm:"type:text" json:"comment,omitempty"`
+	IsSuperseded     bool           `gorm:"not null;default:false" json:"is_superseded"`
+	CreatedAt        time.Time      `json:"created_at"`
+
+	Approver *User `gorm:"foreignKey:ApproverID;references:ID" json:"approver,omitempty"`
+}
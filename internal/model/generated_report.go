@@ -0,0 +1,23 @@
+// internal/model/generated_report.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GeneratedReport 定期生成并保存的绩效报告文件
+type GeneratedReport struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OwnerID     uuid.UUID `gorm:"type:uuid;not null" json:"owner_id"`
+	ScopeType   string    `gorm:"type:varchar(20);not null" json:"scope_type"` // 'user', 'team', 'all'
+	ScopeValue  string    `gorm:"type:varchar(255);not null" json:"scope_value"`
+	PeriodLabel string    `gorm:"type:varchar(50);not null" json:"period_label"`
+	PeriodStart time.Time `gorm:"not null" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"not null" json:"period_end"`
+	Format      string    `gorm:"type:varchar(10);not null" json:"format"` // 'csv', 'xlsx', 'html'
+	FileName    string    `gorm:"type:varchar(255);not null" json:"file_name"`
+	Content     []byte    `gorm:"type:bytea;not null" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
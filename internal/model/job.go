@@ -0,0 +1,25 @@
+// internal/model/job.go
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Job 后台作业队列中的一个作业
+type Job struct {
+	ID          uint64         `gorm:"primaryKey" json:"id"`
+	JobType     string         `gorm:"type:varchar(100);not null" json:"job_type"`
+	Payload     datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"`
+	Status      string         `gorm:"type:varchar(30);not null;default:'pending'" json:"status"` // 'pending', 'running', 'succeeded', 'dead'
+	Attempts    int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int            `gorm:"not null;default:5" json:"max_attempts"`
+	RunAt       time.Time      `gorm:"not null;default:now()" json:"run_at"`
+	LastError   string         `gorm:"type:text" json:"last_error,omitempty"`
+	LockedBy    string         `gorm:"type:varchar(255)" json:"locked_by,omitempty"`
+	LockedAt    *time.Time     `json:"locked_at,omitempty"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
@@ -8,18 +8,30 @@ import (
 )
 
 type PeriodicTask struct {
-	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Title             string     `gorm:"type:varchar(255);not null" json:"title"`
-	Description       string     `gorm:"type:text" json:"description"`
-	CronExpression    string     `gorm:"type:varchar(255);not null" json:"cron_expression"`
-	DefaultAssigneeID *uuid.UUID `json:"default_assignee_id"`
-	DefaultEffort     int        `gorm:"not null" json:"default_effort"`
-	DefaultPriority   string     `gorm:"type:varchar(50);not null" json:"default_priority"`
-	DefaultTaskTypeID *uuid.UUID `json:"default_task_type_id"`
-	IsActive          bool       `gorm:"not null;default:true" json:"is_active"`
-	CreatedByID       uuid.UUID  `gorm:"not null" json:"created_by_id"`
-	StartDate         *time.Time `json:"start_date,omitempty"`
-	EndDate           *time.Time `json:"end_date,omitempty"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	ID                uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Title             string      `gorm:"type:varchar(255);not null" json:"title"`
+	Description       string      `gorm:"type:text" json:"description"`
+	CronExpression    string      `gorm:"type:varchar(255);not null;default:''" json:"cron_expression"`
+	RRule             string      `gorm:"column:rrule;type:text;not null;default:''" json:"rrule,omitempty"` // RFC 5545 RRULE，与 CronExpression 二选一
+	TimeZone          string      `gorm:"type:varchar(64);not null;default:''" json:"time_zone,omitempty"`   // IANA 时区，如 'Asia/Shanghai'，为空时使用服务器时区
+	DefaultAssigneeID *uuid.UUID  `json:"default_assignee_id"`
+	DefaultEffort     int         `gorm:"not null" json:"default_effort"`
+	DefaultPriority   string      `gorm:"type:varchar(50);not null" json:"default_priority"`
+	DefaultTaskTypeID *uuid.UUID  `json:"default_task_type_id"`
+	IsActive          bool        `gorm:"not null;default:true" json:"is_active"`
+	CreatedByID       uuid.UUID   `gorm:"not null" json:"created_by_id"`
+	HolidayPolicy     string      `gorm:"type:varchar(30);not null;default:'none'" json:"holiday_policy"`    // 'none', 'skip', 'next_working_day', 'previous_working_day'
+	LeaveFallback     string      `gorm:"type:varchar(30);not null;default:'none'" json:"leave_fallback"`    // 'none', 'pool', 'backup'
+	BackupAssigneeID  *uuid.UUID  `json:"backup_assignee_id,omitempty"`                                      // 默认负责人请假时的备岗人员
+	RotationStrategy  string      `gorm:"type:varchar(30);not null;default:'none'" json:"rotation_strategy"` // 'none', 'round_robin', 'skip_on_leave', 'least_loaded'
+	RotationPointer   int         `gorm:"not null;default:0" json:"rotation_pointer"`                        // 轮值池中下一位成员的位置
+	RotationUserIDs   []uuid.UUID `gorm:"-" json:"rotation_user_ids,omitempty"`                              // 轮值池成员 (按轮换顺序)，存储在 periodic_task_rotation_members
+	DueOffsetType     string      `gorm:"type:varchar(30);not null;default:'none'" json:"due_offset_type"`   // 'none', 'working_days', 'end_of_week', 'end_of_month'
+	DueOffsetValue    int         `gorm:"not null;default:0" json:"due_offset_value"`                        // 'working_days' 时为工作日天数
+	OverlapPolicy     string      `gorm:"type:varchar(30);not null;default:'always'" json:"overlap_policy"`  // 'always', 'skip_if_open', 'roll_forward'
+	PausedUntil       *time.Time  `json:"paused_until,omitempty"`                                            // 暂停截止时间，之前的触发都会被跳过，之后自动恢复
+	StartDate         *time.Time  `json:"start_date,omitempty"`
+	EndDate           *time.Time  `json:"end_date,omitempty"`
+	CreatedAt         time.Time   `json:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at"`
 }
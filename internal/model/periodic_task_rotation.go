@@ -0,0 +1,16 @@
+// internal/model/periodic_task_rotation.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PeriodicTaskRotationMember 计划任务轮值池中的一名成员，按 Position 顺序轮换
+type PeriodicTaskRotationMember struct {
+	PeriodicTaskID uuid.UUID `gorm:"type:uuid;primary_key" json:"periodic_task_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;primary_key" json:"user_id"`
+	Position       int       `gorm:"not null" json:"position"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,22 @@
+// internal/model/periodic_task_run.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PeriodicTaskRun 记录了计划任务的一次触发 (occurrence) 及其结果
+type PeriodicTaskRun struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	PeriodicTaskID uuid.UUID  `gorm:"not null" json:"periodic_task_id"`
+	ScheduledAt    time.Time  `gorm:"not null" json:"scheduled_at"`
+	TriggerType    string     `gorm:"type:varchar(50);not null" json:"trigger_type"` // 'scheduled', 'catch_up', 'holiday_shift', 'manual'
+	Status         string     `gorm:"type:varchar(50);not null" json:"status"`       // 'running', 'deferred', 'created', 'rolled_forward', 'skipped', 'failed'
+	EffectiveAt    *time.Time `json:"effective_at,omitempty"`                        // 因节假日顺延/提前后，任务实际创建的时间
+	TaskID         *uint      `json:"task_id,omitempty"`
+	Message        string     `gorm:"type:text" json:"message,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+// internal/model/review_sla_policy.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReviewSLAPolicy 定义了审批/评价环节的SLA目标时长
+// Priority 和 TaskTypeID 为空时表示通配，匹配时越具体的策略优先级越高
+type ReviewSLAPolicy struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Stage       string     `gorm:"type:varchar(50);not null" json:"stage"` // 'approval', 'evaluation'
+	Priority    *string    `gorm:"type:varchar(50)" json:"priority,omitempty"`
+	TaskTypeID  *uuid.UUID `json:"task_type_id,omitempty"`
+	TargetHours float64    `gorm:"not null" json:"target_hours"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
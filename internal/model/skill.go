@@ -0,0 +1,58 @@
+// internal/model/skill.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Skill 技能标签
+type Skill struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null;unique" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UserSkill 用户掌握的技能及熟练度 (1-5)
+// Level 为本人自评，VerifiedLevel 为经理核实后的等级，核实后以核实等级为准
+type UserSkill struct {
+	UserID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	SkillID       uuid.UUID  `gorm:"type:uuid;primaryKey" json:"skill_id"`
+	Level         int        `gorm:"not null" json:"level"`
+	VerifiedLevel *int       `json:"verified_level,omitempty"`
+	VerifiedByID  *uuid.UUID `json:"verified_by_id,omitempty"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Skill Skill `gorm:"foreignKey:SkillID;references:ID" json:"skill"`
+}
+
+// EffectiveLevel 返回用于匹配的熟练度：已核实时使用核实等级，否则使用自评等级
+func (us UserSkill) EffectiveLevel() int {
+	if us.VerifiedLevel != nil {
+		return *us.VerifiedLevel
+	}
+	return us.Level
+}
+
+// TaskTypeSkill 某种任务类型默认要求的技能及最低熟练度
+type TaskTypeSkill struct {
+	TaskTypeID uuid.UUID `gorm:"type:uuid;primaryKey" json:"task_type_id"`
+	SkillID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"skill_id"`
+	MinLevel   int       `gorm:"not null;default:1" json:"min_level"`
+
+	Skill Skill `gorm:"foreignKey:SkillID;references:ID" json:"skill"`
+}
+
+// TaskSkill 单个任务要求的技能及最低熟练度，设置后覆盖任务类型的默认要求
+type TaskSkill struct {
+	TaskID   uint      `gorm:"primaryKey" json:"task_id"`
+	SkillID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"skill_id"`
+	MinLevel int       `gorm:"not null;default:1" json:"min_level"`
+
+	Skill Skill `gorm:"foreignKey:SkillID;references:ID" json:"skill"`
+}
@@ -0,0 +1,21 @@
+// internal/model/status_light_threshold.go
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusLightThreshold 按团队或角色覆盖全局的状态灯阈值
+// 负载 <= NormalMax 为 normal，<= BusyMax 为 busy，超过 BusyMax 为 overloaded
+type StatusLightThreshold struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ScopeType  string    `gorm:"type:varchar(20);not null" json:"scope_type"`   // 'team', 'role'
+	ScopeValue string    `gorm:"type:varchar(255);not null" json:"scope_value"` // 团队名或角色名
+	Mode       string    `gorm:"type:varchar(20);not null" json:"mode"`         // 'percentage', 'hours'
+	NormalMax  float64   `gorm:"not null" json:"normal_max"`
+	BusyMax    float64   `gorm:"not null" json:"busy_max"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
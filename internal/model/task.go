@@ -48,6 +48,7 @@ import (
 
 type Task struct {
 	ID               uint           `gorm:"primaryKey" json:"id"`
+	TaskKey          *string        `gorm:"type:varchar(32);unique" json:"task_key,omitempty"` // 人类可读的任务编号，如 'OPS-128'
 	Title            string         `gorm:"type:varchar(255);not null" json:"title"`
 	Description      string         `gorm:"type:text" json:"description"`
 	Status           string         `gorm:"type:varchar(50);not null" json:"status"`
@@ -57,12 +58,15 @@ type Task struct {
 	Evaluation       datatypes.JSON `json:"evaluation,omitempty"`
 	RejectionReason  string         `gorm:"type:text" json:"rejection_reason,omitempty"`
 	DifficultyRating datatypes.JSON `json:"difficulty_rating,omitempty"` // <-- 新增字段
+	// 按评价人打分习惯校准后的评价 (与 Evaluation 同一量纲)，由校准作业计算
+	NormalizedEvaluation datatypes.JSON `json:"normalized_evaluation,omitempty"`
 
 	// --- 关联ID字段 ---
 	CreatorID    uuid.UUID  `json:"creator_id"`
 	TaskTypeID   *uuid.UUID `json:"task_type_id,omitempty"` // <-- 修改在这里，允许为空
 	ReviewerID   *uuid.UUID `json:"reviewer_id,omitempty"`
 	AssigneeID   *uuid.UUID `json:"assignee_id,omitempty"`
+	EvaluatorID  *uuid.UUID `json:"evaluator_id,omitempty"` // 记录是谁完成了评价
 	ParentTaskID *uint      `gorm:"index" json:"parent_task_id,omitempty"`
 
 	// --- GORM关联关系 ---
@@ -75,6 +79,7 @@ type Task struct {
 	ApprovedAt  *time.Time `json:"approved_at,omitempty"`
 	ClaimedAt   *time.Time `json:"claimed_at,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty"` // 提交评价 (进入 pending_evaluation) 的时间
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
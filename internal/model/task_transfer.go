@@ -8,12 +8,26 @@ import (
 )
 
 type TaskTransfer struct {
-	ID                     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	TaskID                 uint      `gorm:"not null"`
-	FromUserID             uuid.UUID `gorm:"not null"`
-	ToUserID               uuid.UUID `gorm:"not null"`
-	EffortSpentByInitiator int       `gorm:"not null"`
-	Status                 string    `gorm:"type:varchar(50);not null;default:'pending'"`
-	CreatedAt              time.Time
-	UpdatedAt              time.Time
+	ID                     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TaskID                 uint      `gorm:"not null" json:"task_id"`
+	FromUserID             uuid.UUID `gorm:"not null" json:"from_user_id"`
+	ToUserID               uuid.UUID `gorm:"not null" json:"to_user_id"`
+	EffortSpentByInitiator int       `gorm:"not null" json:"effort_spent_by_initiator"`
+	HandoffNotes           string    `gorm:"type:text" json:"handoff_notes"`
+	Status                 string    `gorm:"type:varchar(50);not null;default:'pending'" json:"status"` // 'pending_approval', 'pending', 'accepted', 'rejected', 'denied', 'cancelled', 'expired'
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+
+	// --- 转交策略判定与经理审批记录 ---
+	RequiresApproval bool       `gorm:"not null;default:false" json:"requires_approval"`
+	PolicyReason     string     `gorm:"type:varchar(255)" json:"policy_reason,omitempty"` // 策略判定的原因，如 'same_team', 'cross_team'
+	ApproverID       *uuid.UUID `json:"approver_id,omitempty"`
+	ApprovalDecision string     `gorm:"type:varchar(50)" json:"approval_decision,omitempty"` // 'approved', 'denied'
+	ApprovalComment  string     `gorm:"type:text" json:"approval_comment,omitempty"`
+	DecidedAt        *time.Time `json:"decided_at,omitempty"`
+
+	// --- GORM关联关系 (仅用于收件箱/发件箱的预加载) ---
+	Task     *Task `gorm:"foreignKey:TaskID;references:ID" json:"task,omitempty"`
+	FromUser *User `gorm:"foreignKey:FromUserID;references:ID" json:"from_user,omitempty"`
+	ToUser   *User `gorm:"foreignKey:ToUserID;references:ID" json:"to_user,omitempty"`
 }
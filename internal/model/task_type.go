@@ -12,6 +12,7 @@ import (
 type TaskType struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Name      string    `gorm:"type:varchar(255);unique_not_null" json:"name"`
+	KeyPrefix *string   `gorm:"type:varchar(16);unique" json:"key_prefix,omitempty"` // 任务编号前缀，如 'OPS'
 	IsEnabled bool      `gorm:"default:true" json:"is_enabled"`
 	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
 	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
@@ -21,6 +21,13 @@ func CreateTaskType(taskType *model.TaskType) error {
 	return result.Error
 }
 
+// FindTaskTypeByID 根据ID查找一个任务类型
+func FindTaskTypeByID(id uuid.UUID) (model.TaskType, error) {
+	var taskType model.TaskType
+	result := config.DB.First(&taskType, "id = ?", id)
+	return taskType, result.Error
+}
+
 // UpdateTaskType 更新一个任务类型的名称或启用状态
 func UpdateTaskType(id uuid.UUID, name string, isEnabled bool) error {
 	return config.DB.Model(&model.TaskType{}).Where("id = ?", id).Updates(map[string]interface{}{
@@ -29,6 +36,11 @@ func UpdateTaskType(id uuid.UUID, name string, isEnabled bool) error {
 	}).Error
 }
 
+// UpdateTaskTypeKeyPrefix 更新一个任务类型的任务编号前缀，nil 表示清除前缀
+func UpdateTaskTypeKeyPrefix(id uuid.UUID, keyPrefix *string) error {
+	return config.DB.Model(&model.TaskType{}).Where("id = ?", id).Update("key_prefix", keyPrefix).Error
+}
+
 // IsTaskTypeInUse 检查一个任务类型是否已被任何任务使用
 func IsTaskTypeInUse(id uuid.UUID) (bool, error) {
 	var count int64
@@ -0,0 +1,46 @@
+// internal/repository/analytics_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FlowTaskFilter 流动分析的任务筛选条件，为空的条件不生效
+// Team 按负责人的团队匹配，尚未分配负责人的任务按创建人的团队匹配
+type FlowTaskFilter struct {
+	TaskTypeID *uuid.UUID
+	Priority   string
+	Team       string
+}
+
+// ListTasksForFlow 获取在 [start, end) 期间存在过的任务：创建于 end 之前，且未完成或完成于 start 之后
+// 只查询流动分析需要的状态和时间戳字段
+func ListTasksForFlow(start, end time.Time, filter FlowTaskFilter) ([]model.Task, error) {
+	var tasks []model.Task
+	query := config.DB.Table("tasks").
+		Select("tasks.id, tasks.status, tasks.priority, tasks.effort, tasks.task_type_id, tasks.assignee_id, "+
+			"tasks.created_at, tasks.approved_at, tasks.claimed_at, tasks.submitted_at, tasks.completed_at, tasks.updated_at").
+		Where("tasks.created_at < ?", end).
+		Where("tasks.completed_at IS NULL OR tasks.completed_at >= ?", start)
+
+	if filter.TaskTypeID != nil {
+		query = query.Where("tasks.task_type_id = ?", *filter.TaskTypeID)
+	}
+	if filter.Priority != "" {
+		query = query.Where("tasks.priority = ?", filter.Priority)
+	}
+	if team := strings.TrimSpace(filter.Team); team != "" {
+		query = query.
+			Joins("JOIN users creators ON creators.id = tasks.creator_id").
+			Joins("LEFT JOIN users assignees ON assignees.id = tasks.assignee_id").
+			Where("LOWER(TRIM(CASE WHEN tasks.assignee_id IS NOT NULL THEN assignees.team ELSE creators.team END)) = LOWER(?)", team)
+	}
+
+	err := query.Order("tasks.id asc").Find(&tasks).Error
+	return tasks, err
+}
@@ -0,0 +1,115 @@
+// internal/repository/approval_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- 多级审批策略 ---
+
+// ListApprovalPolicies 获取所有审批策略
+func ListApprovalPolicies() ([]model.ApprovalPolicy, error) {
+	var policies []model.ApprovalPolicy
+	err := config.DB.Order("created_at asc").Find(&policies).Error
+	return policies, err
+}
+
+// ListActiveApprovalPolicies 获取所有启用的审批策略
+func ListActiveApprovalPolicies() ([]model.ApprovalPolicy, error) {
+	var policies []model.ApprovalPolicy
+	err := config.DB.Where("is_active = ?", true).Find(&policies).Error
+	return policies, err
+}
+
+func FindApprovalPolicyByID(id uuid.UUID) (model.ApprovalPolicy, error) {
+	var policy model.ApprovalPolicy
+	err := config.DB.First(&policy, "id = ?", id).Error
+	return policy, err
+}
+
+func CreateApprovalPolicy(policy *model.ApprovalPolicy) error {
+	return config.DB.Create(policy).Error
+}
+
+func UpdateApprovalPolicy(policy *model.ApprovalPolicy) error {
+	return config.DB.Save(policy).Error
+}
+
+func DeleteApprovalPolicy(id uuid.UUID) error {
+	return config.DB.Where("id = ?", id).Delete(&model.ApprovalPolicy{}).Error
+}
+
+// --- 任务审批记录 ---
+
+// RecordTaskApproval 在一个事务中登记一位审批人的批准：先锁定任务行，任务已不在待审批状态时不做任何修改并返回 false
+// 登记后以本轮全部批准记录 (含本次) 调用 decide；decide 返回错误时整个事务回滚，返回非空的 taskUpdates 时任务在同一事务中放行
+func RecordTaskApproval(approval *model.TaskApproval, decide func(task model.Task, approvals []model.TaskApproval) (map[string]interface{}, error)) (bool, error) {
+	recorded := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var task model.Task
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, approval.TaskID).Error; err != nil {
+			return err
+		}
+		if task.Status != "pending_review" {
+			return nil
+		}
+		if err := tx.Create(approval).Error; err != nil {
+			return err
+		}
+		var approvals []model.TaskApproval
+		if err := tx.Where("task_id = ? AND decision = ? AND is_superseded = ?", task.ID, "approved", false).
+			Order("created_at asc").Find(&approvals).Error; err != nil {
+			return err
+		}
+		taskUpdates, err := decide(task, approvals)
+		if err != nil {
+			return err
+		}
+		if taskUpdates != nil {
+			if err := tx.Model(&model.Task{}).Where("id = ? AND status = ?", task.ID, "pending_review").Updates(taskUpdates).Error; err != nil {
+				return err
+			}
+		}
+		recorded = true
+		return nil
+	})
+	return recorded, err
+}
+
+// ListTaskApprovals 获取一个任务的全部审批历史
+func ListTaskApprovals(taskID uint) ([]model.TaskApproval, error) {
+	var approvals []model.TaskApproval
+	err := config.DB.Preload("Approver").Where("task_id = ?", taskID).Order("created_at asc").Find(&approvals).Error
+	return approvals, err
+}
+
+// RejectTaskWithApproval 在一个事务中驳回任务：先作废本轮已有的审批记录，再登记驳回决定，最后更新任务
+// 仅当任务仍处于待审批状态时生效，任务状态已被其他请求改变时不做任何修改，返回 false
+func RejectTaskWithApproval(taskID uint, rejection *model.TaskApproval, taskUpdates map[string]interface{}) (bool, error) {
+	changed := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Task{}).Where("id = ? AND status = ?", taskID, "pending_review").Updates(taskUpdates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		if err := tx.Model(&model.TaskApproval{}).
+			Where("task_id = ? AND is_superseded = ?", taskID, false).
+			Update("is_superseded", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(rejection).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
@@ -0,0 +1,39 @@
+// internal/repository/calibration_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ListEvaluationsForCalibration 获取有评价人记录的已评价任务，since 不为空时只取该时间之后完成的任务
+func ListEvaluationsForCalibration(since *time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	query := config.DB.Select("id", "assignee_id", "evaluator_id", "evaluation", "completed_at").
+		Where("status = 'completed' AND evaluation IS NOT NULL AND evaluator_id IS NOT NULL")
+	if since != nil {
+		query = query.Where("completed_at >= ?", *since)
+	}
+	err := query.Order("id asc").Find(&tasks).Error
+	return tasks, err
+}
+
+// UpdateNormalizedEvaluations 在一个事务中批量写入任务的校准评价分 (不更新 updated_at)
+func UpdateNormalizedEvaluations(values map[uint]datatypes.JSON) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		for taskID, value := range values {
+			if err := tx.Model(&model.Task{}).Where("id = ?", taskID).
+				UpdateColumn("normalized_evaluation", value).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
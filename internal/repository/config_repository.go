@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // --- 法定节假日 (Holidays) 相关 ---
@@ -51,6 +52,53 @@ func GetSystemConfigValueByKey(key string) (string, error) {
 	err := config.DB.First(&cfg, "config_key = ?", key).Error
 	return cfg.ConfigValue, err
 }
+
+// UpdateSystemConfig 更新一个已存在的系统配置项，配置项不存在时返回 gorm.ErrRecordNotFound
 func UpdateSystemConfig(key, value string) error {
-	return config.DB.Model(&model.SystemConfig{}).Where("config_key = ?", key).Update("config_value", value).Error
+	result := config.DB.Model(&model.SystemConfig{}).Where("config_key = ?", key).Update("config_value", value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListSystemConfigs 获取所有系统配置项
+func ListSystemConfigs() ([]model.SystemConfig, error) {
+	var configs []model.SystemConfig
+	err := config.DB.Order("config_key asc").Find(&configs).Error
+	return configs, err
+}
+
+// --- 状态灯阈值 (Status Light Thresholds) 相关 ---
+
+// ListStatusLightThresholds 获取所有按团队/角色覆盖的状态灯阈值
+func ListStatusLightThresholds() ([]model.StatusLightThreshold, error) {
+	var thresholds []model.StatusLightThreshold
+	err := config.DB.Order("scope_type asc, scope_value asc").Find(&thresholds).Error
+	return thresholds, err
+}
+
+// FindStatusLightThresholdByID 根据ID查找一条状态灯阈值
+func FindStatusLightThresholdByID(id uuid.UUID) (model.StatusLightThreshold, error) {
+	var threshold model.StatusLightThreshold
+	err := config.DB.First(&threshold, "id = ?", id).Error
+	return threshold, err
+}
+
+// CreateStatusLightThreshold 创建一条状态灯阈值
+func CreateStatusLightThreshold(threshold *model.StatusLightThreshold) error {
+	return config.DB.Create(threshold).Error
+}
+
+// UpdateStatusLightThreshold 更新一条状态灯阈值
+func UpdateStatusLightThreshold(threshold *model.StatusLightThreshold) error {
+	return config.DB.Save(threshold).Error
+}
+
+// DeleteStatusLightThreshold 删除一条状态灯阈值
+func DeleteStatusLightThreshold(id uuid.UUID) error {
+	return config.DB.Where("id = ?", id).Delete(&model.StatusLightThreshold{}).Error
 }
@@ -0,0 +1,150 @@
+// internal/repository/job_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateJob 将一个作业加入队列；tx 不为 nil 时在调用方的事务中入队，与业务数据一起提交或回滚
+func CreateJob(tx *gorm.DB, job *model.Job) error {
+	if tx == nil {
+		tx = config.DB
+	}
+	return tx.Create(job).Error
+}
+
+// CreateJobUnlessPending 仅当队列中没有同类型的待执行作业时才将作业入队，返回是否入队
+// 通过按作业类型加事务级咨询锁，保证并发调用时只会有一个作业入队
+func CreateJobUnlessPending(job *model.Job) (bool, error) {
+	created := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "jobs:"+job.JobType).Error; err != nil {
+			return err
+		}
+		var pending int64
+		if err := tx.Model(&model.Job{}).Where("job_type = ? AND status = ?", job.JobType, "pending").Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return nil
+		}
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// ClaimNextJob 领取一个到期的待执行作业并标记为执行中
+// 使用 FOR UPDATE SKIP LOCKED，多个 worker (包括多个实例) 并发领取时不会拿到同一个作业
+func ClaimNextJob(workerID string) (model.Job, bool, error) {
+	var jobs []model.Job
+	err := config.DB.Raw(`
+		UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_by = ?, locked_at = now(), updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'pending' AND run_at <= now()
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`, workerID).Scan(&jobs).Error
+	if err != nil || len(jobs) == 0 {
+		return model.Job{}, false, err
+	}
+	return jobs[0], true, nil
+}
+
+// MarkJobSucceeded 将作业标记为成功
+// 只有作业仍由 workerID 持有时才会生效 (作业可能因超时被重新排队并由其他 worker 领取)，返回是否更新
+func MarkJobSucceeded(id uint64, workerID string) (bool, error) {
+	result := config.DB.Model(&model.Job{}).Where("id = ? AND status = ? AND locked_by = ?", id, "running", workerID).Updates(map[string]interface{}{
+		"status":       "succeeded",
+		"last_error":   "",
+		"completed_at": time.Now(),
+	})
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkJobFailed 记录作业失败；dead 为 true 时转入死信，否则在 nextRunAt 重新排队
+// 与 MarkJobSucceeded 一样，只有作业仍由 workerID 持有时才会生效，返回是否更新
+func MarkJobFailed(id uint64, workerID string, errMessage string, nextRunAt time.Time, dead bool) (bool, error) {
+	updates := map[string]interface{}{
+		"status":     "pending",
+		"last_error": errMessage,
+		"run_at":     nextRunAt,
+		"locked_by":  nil,
+		"locked_at":  nil,
+	}
+	if dead {
+		updates["status"] = "dead"
+		updates["completed_at"] = time.Now()
+	}
+	result := config.DB.Model(&model.Job{}).Where("id = ? AND status = ? AND locked_by = ?", id, "running", workerID).Updates(updates)
+	return result.RowsAffected == 1, result.Error
+}
+
+// RequeueStaleJobs 处理长时间处于执行中的作业 (例如执行它的实例在中途崩溃或卡住)
+// 与正常的失败路径一致：领取时已计入一次尝试，尝试次数已用完的转入死信，其余重新排队；返回重新排队和转入死信的数量
+func RequeueStaleJobs(lockedBefore time.Time) (int64, int64, error) {
+	const staleError = "job timed out or its worker stopped before it finished"
+	var requeued, dead int64
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Job{}).
+			Where("status = ? AND locked_at < ? AND attempts >= max_attempts", "running", lockedBefore).
+			Updates(map[string]interface{}{"status": "dead", "last_error": staleError, "locked_by": nil, "locked_at": nil, "completed_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		dead = result.RowsAffected
+
+		result = tx.Model(&model.Job{}).
+			Where("status = ? AND locked_at < ?", "running", lockedBefore).
+			Updates(map[string]interface{}{"status": "pending", "last_error": staleError, "locked_by": nil, "locked_at": nil, "run_at": time.Now()})
+		if result.Error != nil {
+			return result.Error
+		}
+		requeued = result.RowsAffected
+		return nil
+	})
+	return requeued, dead, err
+}
+
+// ListJobs 按状态 (为空时不过滤) 查询作业，最新的在前
+func ListJobs(status, jobType string, limit int) ([]model.Job, error) {
+	var jobs []model.Job
+	query := config.DB.Order("created_at desc").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if jobType != "" {
+		query = query.Where("job_type = ?", jobType)
+	}
+	err := query.Find(&jobs).Error
+	return jobs, err
+}
+
+// FindJobByID 根据ID查询作业
+func FindJobByID(id uint64) (model.Job, error) {
+	var job model.Job
+	err := config.DB.First(&job, id).Error
+	return job, err
+}
+
+// RetryJob 将死信作业重新排队并重置重试次数
+func RetryJob(id uint64) error {
+	return config.DB.Model(&model.Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       "pending",
+		"attempts":     0,
+		"run_at":       time.Now(),
+		"locked_by":    nil,
+		"locked_at":    nil,
+		"completed_at": nil,
+	}).Error
+}
@@ -59,3 +59,38 @@ func ListLeaveDatesInRange(userID uuid.UUID, start, end time.Time) (map[string]b
 	}
 	return leaveDateMap, nil
 }
+
+// FindInProgressTasksForUsers 一次性获取多个用户所有正在进行的任务
+func FindInProgressTasksForUsers(userIDs []uuid.UUID) ([]model.Task, error) {
+	var tasks []model.Task
+	if len(userIDs) == 0 {
+		return tasks, nil
+	}
+	err := config.DB.Where("assignee_id IN ? AND status = ?", userIDs, "in_progress").Find(&tasks).Error
+	return tasks, err
+}
+
+// ListLeaveDatesForUsersInRange 一次性获取多个用户在指定日期范围内的请假日期 (用户ID -> 日期集合)
+func ListLeaveDatesForUsersInRange(userIDs []uuid.UUID, start, end time.Time) (map[uuid.UUID]map[string]bool, error) {
+	result := make(map[uuid.UUID]map[string]bool)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var leaves []model.Leave
+	err := config.DB.Where("user_id IN ? AND start_date <= ? AND end_date >= ?", userIDs, end, start).Find(&leaves).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leave := range leaves {
+		dates, ok := result[leave.UserID]
+		if !ok {
+			dates = make(map[string]bool)
+			result[leave.UserID] = dates
+		}
+		for current := leave.StartDate; !current.After(leave.EndDate); current = current.AddDate(0, 0, 1) {
+			dates[current.Format("2006-01-02")] = true
+		}
+	}
+	return result, nil
+}
@@ -4,8 +4,11 @@ package repository
 import (
 	"gotasksys/internal/config"
 	"gotasksys/internal/model"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func FindPeriodicTaskByID(id uuid.UUID) (model.PeriodicTask, error) {
@@ -31,3 +34,98 @@ func UpdatePeriodicTask(pt *model.PeriodicTask) error {
 func DeletePeriodicTask(id uuid.UUID) error {
 	return config.DB.Where("id = ?", id).Delete(&model.PeriodicTask{}).Error
 }
+
+// --- 计划任务执行记录 ---
+
+// ClaimPeriodicTaskRun 尝试登记一次计划触发，如果该规则的同一触发时间已被登记过，则返回 false
+func ClaimPeriodicTaskRun(run *model.PeriodicTaskRun) (bool, error) {
+	result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdatePeriodicTaskRun 更新一次计划触发的执行结果
+func UpdatePeriodicTaskRun(id uuid.UUID, updates map[string]interface{}) error {
+	return config.DB.Model(&model.PeriodicTaskRun{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// FindLatestPeriodicTaskRun 获取一个规则在 before 之前最近一次登记的计划触发记录
+// (因节假日提前创建的触发，其计划时间可能晚于当前时间，需要排除；手动触发不属于计划，也需要排除)
+func FindLatestPeriodicTaskRun(periodicTaskID uuid.UUID, before time.Time) (model.PeriodicTaskRun, error) {
+	var run model.PeriodicTaskRun
+	err := config.DB.Where("periodic_task_id = ? AND scheduled_at <= ? AND trigger_type <> ?", periodicTaskID, before, "manual").
+		Order("scheduled_at desc").First(&run).Error
+	return run, err
+}
+
+// FindPeriodicTaskRun 获取一个规则在指定触发时间的触发记录
+func FindPeriodicTaskRun(periodicTaskID uuid.UUID, scheduledAt time.Time) (model.PeriodicTaskRun, error) {
+	var run model.PeriodicTaskRun
+	err := config.DB.Where("periodic_task_id = ? AND scheduled_at = ?", periodicTaskID, scheduledAt).First(&run).Error
+	return run, err
+}
+
+// ListDueDeferredPeriodicTaskRuns 获取已到实际创建时间的顺延触发
+func ListDueDeferredPeriodicTaskRuns(now time.Time) ([]model.PeriodicTaskRun, error) {
+	var runs []model.PeriodicTaskRun
+	err := config.DB.Where("status = ? AND effective_at <= ?", "deferred", now).Order("effective_at asc").Find(&runs).Error
+	return runs, err
+}
+
+// ClaimDeferredPeriodicTaskRun 将一个顺延触发从 'deferred' 切换为 'running'，已被处理过则返回 false
+func ClaimDeferredPeriodicTaskRun(id uuid.UUID) (bool, error) {
+	result := config.DB.Model(&model.PeriodicTaskRun{}).
+		Where("id = ? AND status = ?", id, "deferred").
+		Update("status", "running")
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListPeriodicTaskRuns 获取一个规则最近的触发记录
+func ListPeriodicTaskRuns(periodicTaskID uuid.UUID, limit int) ([]model.PeriodicTaskRun, error) {
+	var runs []model.PeriodicTaskRun
+	err := config.DB.Where("periodic_task_id = ?", periodicTaskID).Order("scheduled_at desc").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// FindOpenTaskFromPeriodicRule 获取规则生成的、仍处于指定状态的最近一个任务
+func FindOpenTaskFromPeriodicRule(periodicTaskID uuid.UUID, statuses []string) (model.Task, error) {
+	var task model.Task
+	err := config.DB.Joins("JOIN periodic_task_runs ON periodic_task_runs.task_id = tasks.id").
+		Where("periodic_task_runs.periodic_task_id = ? AND tasks.status IN ?", periodicTaskID, statuses).
+		Order("tasks.created_at desc").First(&task).Error
+	return task, err
+}
+
+// --- 计划任务轮值池 ---
+
+// ListRotationMembers 获取一个或多个规则的轮值池成员，按轮换顺序排列
+func ListRotationMembers(periodicTaskIDs []uuid.UUID) ([]model.PeriodicTaskRotationMember, error) {
+	var members []model.PeriodicTaskRotationMember
+	if len(periodicTaskIDs) == 0 {
+		return members, nil
+	}
+	err := config.DB.Where("periodic_task_id IN (?)", periodicTaskIDs).Order("periodic_task_id, position asc").Find(&members).Error
+	return members, err
+}
+
+// ReplaceRotationMembers 用新的成员列表替换一个规则的轮值池
+func ReplaceRotationMembers(periodicTaskID uuid.UUID, userIDs []uuid.UUID) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("periodic_task_id = ?", periodicTaskID).Delete(&model.PeriodicTaskRotationMember{}).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+		members := make([]model.PeriodicTaskRotationMember, 0, len(userIDs))
+		for i, userID := range userIDs {
+			members = append(members, model.PeriodicTaskRotationMember{PeriodicTaskID: periodicTaskID, UserID: userID, Position: i})
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+// UpdateRotationPointer 持久化轮值指针
+// 使用 UpdateColumn 不更新 updated_at，避免 leader 将其视为规则变更而重新加载作业
+func UpdateRotationPointer(periodicTaskID uuid.UUID, pointer int) error {
+	return config.DB.Model(&model.PeriodicTask{}).Where("id = ?", periodicTaskID).UpdateColumn("rotation_pointer", pointer).Error
+}
@@ -0,0 +1,95 @@
+// internal/repository/report_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+)
+
+// ListCompletedTasksForUsersInRange 获取指定用户在 [start, end) 期间完成的任务 (按完成时间排序)
+func ListCompletedTasksForUsersInRange(userIDs []uuid.UUID, start, end time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	if len(userIDs) == 0 {
+		return tasks, nil
+	}
+	err := config.DB.Where("assignee_id IN ? AND status = 'completed'", userIDs).
+		Where("completed_at >= ? AND completed_at < ?", start, end).
+		Order("completed_at asc").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// CountRejectionsForCreatorsInRange 统计指定用户创建的任务在 [start, end) 期间被审批驳回、需要返工的次数
+func CountRejectionsForCreatorsInRange(userIDs []uuid.UUID, start, end time.Time) (map[uuid.UUID]int, error) {
+	result := make(map[uuid.UUID]int)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		CreatorID uuid.UUID
+		Count     int
+	}
+	err := config.DB.Table("task_approvals").
+		Select("tasks.creator_id, COUNT(*) AS count").
+		Joins("JOIN tasks ON tasks.id = task_approvals.task_id").
+		Where("task_approvals.decision = 'rejected' AND tasks.creator_id IN ?", userIDs).
+		Where("task_approvals.created_at >= ? AND task_approvals.created_at < ?", start, end).
+		Group("tasks.creator_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.CreatorID] = row.Count
+	}
+	return result, nil
+}
+
+// ListAcceptedTransfersForUsersInRange 获取 [start, end) 期间被接受的、转出或转入指定用户的转交记录
+func ListAcceptedTransfersForUsersInRange(userIDs []uuid.UUID, start, end time.Time) ([]model.TaskTransfer, error) {
+	var transfers []model.TaskTransfer
+	if len(userIDs) == 0 {
+		return transfers, nil
+	}
+	err := config.DB.Where("status = 'accepted' AND (from_user_id IN ? OR to_user_id IN ?)", userIDs, userIDs).
+		Where("updated_at >= ? AND updated_at < ?", start, end).
+		Find(&transfers).Error
+	return transfers, err
+}
+
+// --- 已生成的报告 ---
+
+// SaveGeneratedReport 保存一份报告，同一经理、范围、周期和格式的报告重复生成时覆盖旧内容
+func SaveGeneratedReport(report *model.GeneratedReport) error {
+	return config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "owner_id"}, {Name: "scope_type"}, {Name: "scope_value"}, {Name: "period_label"}, {Name: "format"}},
+		DoUpdates: clause.AssignmentColumns([]string{"period_start", "period_end", "file_name", "content", "created_at"}),
+	}).Create(report).Error
+}
+
+// ListGeneratedReportFormats 获取一位经理某个周期已生成的报告格式
+func ListGeneratedReportFormats(ownerID uuid.UUID, periodLabel string) ([]string, error) {
+	var formats []string
+	err := config.DB.Model(&model.GeneratedReport{}).
+		Where("owner_id = ? AND period_label = ?", ownerID, periodLabel).
+		Distinct().Pluck("format", &formats).Error
+	return formats, err
+}
+
+// ListGeneratedReports 获取一位经理的所有报告 (不加载文件内容)
+func ListGeneratedReports(ownerID uuid.UUID) ([]model.GeneratedReport, error) {
+	var reports []model.GeneratedReport
+	err := config.DB.Omit("content").Where("owner_id = ?", ownerID).Order("created_at desc").Find(&reports).Error
+	return reports, err
+}
+
+// FindGeneratedReportByID 根据ID查找报告 (包含文件内容)
+func FindGeneratedReportByID(id uuid.UUID) (model.GeneratedReport, error) {
+	var report model.GeneratedReport
+	err := config.DB.First(&report, "id = ?", id).Error
+	return report, err
+}
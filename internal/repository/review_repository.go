@@ -0,0 +1,87 @@
+// internal/repository/review_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- 审核队列 ---
+
+// ListTasksAwaitingApproval 获取所有等待审批的任务
+func ListTasksAwaitingApproval() ([]model.Task, error) {
+	var tasks []model.Task
+	err := config.DB.Preload("Creator").Preload("Assignee").
+		Where("status = ?", "pending_review").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListMainTasksAwaitingEvaluation 获取所有等待评价的主任务 (子任务由父任务负责人评价，不进入经理的审核队列)
+func ListMainTasksAwaitingEvaluation() ([]model.Task, error) {
+	var tasks []model.Task
+	err := config.DB.Preload("Creator").Preload("Assignee").
+		Where("status = ? AND parent_task_id IS NULL", "pending_evaluation").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListTasksApprovedSince 获取指定时间之后被批准的任务，用于计算审批人的审批时效
+func ListTasksApprovedSince(since time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := config.DB.Where("approved_at IS NOT NULL AND reviewer_id IS NOT NULL AND approved_at >= ?", since).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// ListTasksEvaluatedSince 获取指定时间之后完成评价的任务，用于计算评价人的评价时效
+func ListTasksEvaluatedSince(since time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	err := config.DB.Where("status = ? AND evaluator_id IS NOT NULL AND submitted_at IS NOT NULL AND completed_at >= ?", "completed", since).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// FindUsersByIDs 根据一组ID批量查询用户
+func FindUsersByIDs(ids []uuid.UUID) ([]model.User, error) {
+	var users []model.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := config.DB.Where("id IN (?)", ids).Find(&users).Error
+	return users, err
+}
+
+// --- 审核SLA策略 ---
+
+// ListReviewSLAPolicies 获取所有审核SLA策略
+func ListReviewSLAPolicies() ([]model.ReviewSLAPolicy, error) {
+	var policies []model.ReviewSLAPolicy
+	err := config.DB.Order("stage asc, created_at asc").Find(&policies).Error
+	return policies, err
+}
+
+// FindReviewSLAPolicyByID 根据ID查找一条审核SLA策略
+func FindReviewSLAPolicyByID(id uuid.UUID) (model.ReviewSLAPolicy, error) {
+	var policy model.ReviewSLAPolicy
+	err := config.DB.First(&policy, "id = ?", id).Error
+	return policy, err
+}
+
+// CreateReviewSLAPolicy 创建一条审核SLA策略
+func CreateReviewSLAPolicy(policy *model.ReviewSLAPolicy) error {
+	return config.DB.Create(policy).Error
+}
+
+// UpdateReviewSLAPolicy 更新一条审核SLA策略
+func UpdateReviewSLAPolicy(policy *model.ReviewSLAPolicy) error {
+	return config.DB.Save(policy).Error
+}
+
+// DeleteReviewSLAPolicy 删除一条审核SLA策略
+func DeleteReviewSLAPolicy(id uuid.UUID) error {
+	return config.DB.Where("id = ?", id).Delete(&model.ReviewSLAPolicy{}).Error
+}
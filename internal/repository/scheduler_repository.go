@@ -0,0 +1,29 @@
+// internal/repository/scheduler_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"time"
+)
+
+// TryAcquireSchedulerLease 尝试获取或续约一个调度器租约
+// 只有当租约不存在、已过期，或本来就由 holderID 持有时才会成功；过期时间以数据库时钟为准，避免各实例时钟不一致
+func TryAcquireSchedulerLease(name, holderID string, ttl time.Duration) (bool, error) {
+	result := config.DB.Exec(`
+		INSERT INTO scheduler_leases (name, holder_id, expires_at, updated_at)
+		VALUES (?, ?, now() + make_interval(secs => ?), now())
+		ON CONFLICT (name) DO UPDATE
+		SET holder_id = EXCLUDED.holder_id, expires_at = EXCLUDED.expires_at, updated_at = now()
+		WHERE scheduler_leases.holder_id = EXCLUDED.holder_id OR scheduler_leases.expires_at < now()
+	`, name, holderID, ttl.Seconds())
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsSchedulerLeaseHeld 判断租约当前是否仍由 holderID 持有且未过期 (以数据库时钟为准)
+func IsSchedulerLeaseHeld(name, holderID string) (bool, error) {
+	var count int64
+	err := config.DB.Table("scheduler_leases").
+		Where("name = ? AND holder_id = ? AND expires_at > now()", name, holderID).
+		Count(&count).Error
+	return count > 0, err
+}
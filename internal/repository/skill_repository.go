@@ -0,0 +1,163 @@
+// internal/repository/skill_repository.go
+package repository
+
+import (
+	"gotasksys/internal/config"
+	"gotasksys/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- 技能标签库 ---
+
+// ListSkills 获取所有技能标签
+func ListSkills() ([]model.Skill, error) {
+	var skills []model.Skill
+	err := config.DB.Order("name asc").Find(&skills).Error
+	return skills, err
+}
+
+// FindSkillByID 根据ID查找技能标签
+func FindSkillByID(id uuid.UUID) (model.Skill, error) {
+	var skill model.Skill
+	err := config.DB.First(&skill, "id = ?", id).Error
+	return skill, err
+}
+
+// CountSkillsByIDs 统计给定ID中实际存在的技能数量，用于校验输入
+func CountSkillsByIDs(ids []uuid.UUID) (int64, error) {
+	var count int64
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err := config.DB.Model(&model.Skill{}).Where("id IN ?", ids).Count(&count).Error
+	return count, err
+}
+
+// CreateSkill 创建技能标签
+func CreateSkill(skill *model.Skill) error {
+	return config.DB.Create(skill).Error
+}
+
+// UpdateSkill 更新技能标签
+func UpdateSkill(skill *model.Skill) error {
+	return config.DB.Save(skill).Error
+}
+
+// DeleteSkill 删除技能标签 (关联的用户技能和任务要求会被级联删除)
+func DeleteSkill(id uuid.UUID) error {
+	return config.DB.Where("id = ?", id).Delete(&model.Skill{}).Error
+}
+
+// --- 用户技能 ---
+
+// ListUserSkills 获取一个用户的所有技能
+func ListUserSkills(userID uuid.UUID) ([]model.UserSkill, error) {
+	var skills []model.UserSkill
+	err := config.DB.Preload("Skill").Where("user_id = ?", userID).Find(&skills).Error
+	return skills, err
+}
+
+// ListUserSkillsForUsers 一次性获取多个用户的技能
+func ListUserSkillsForUsers(userIDs []uuid.UUID) ([]model.UserSkill, error) {
+	var skills []model.UserSkill
+	if len(userIDs) == 0 {
+		return skills, nil
+	}
+	err := config.DB.Where("user_id IN ?", userIDs).Find(&skills).Error
+	return skills, err
+}
+
+// UpsertUserSkillLevel 新增或更新用户的自评等级，不影响经理核实的等级
+func UpsertUserSkillLevel(userID, skillID uuid.UUID, level int) error {
+	userSkill := model.UserSkill{UserID: userID, SkillID: skillID, Level: level}
+	return config.DB.Omit("Skill").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "skill_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"level": level, "updated_at": time.Now()}),
+	}).Create(&userSkill).Error
+}
+
+// VerifyUserSkillLevel 记录经理核实的等级，用户尚未自评该技能时以核实等级作为自评等级一并创建
+func VerifyUserSkillLevel(userID, skillID uuid.UUID, level int, verifierID uuid.UUID) error {
+	now := time.Now()
+	userSkill := model.UserSkill{
+		UserID: userID, SkillID: skillID, Level: level,
+		VerifiedLevel: &level, VerifiedByID: &verifierID, VerifiedAt: &now,
+	}
+	return config.DB.Omit("Skill").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "skill_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"verified_level": level, "verified_by_id": verifierID, "verified_at": now, "updated_at": now,
+		}),
+	}).Create(&userSkill).Error
+}
+
+// DeleteUserSkill 删除用户的一项技能
+func DeleteUserSkill(userID, skillID uuid.UUID) error {
+	return config.DB.Where("user_id = ? AND skill_id = ?", userID, skillID).Delete(&model.UserSkill{}).Error
+}
+
+// --- 任务技能要求 ---
+
+// ListTaskTypeSkills 获取一种任务类型默认要求的技能
+func ListTaskTypeSkills(taskTypeID uuid.UUID) ([]model.TaskTypeSkill, error) {
+	var skills []model.TaskTypeSkill
+	err := config.DB.Preload("Skill").Where("task_type_id = ?", taskTypeID).Find(&skills).Error
+	return skills, err
+}
+
+// ListTaskTypeSkillsForTypes 一次性获取多种任务类型的技能要求
+func ListTaskTypeSkillsForTypes(taskTypeIDs []uuid.UUID) ([]model.TaskTypeSkill, error) {
+	var skills []model.TaskTypeSkill
+	if len(taskTypeIDs) == 0 {
+		return skills, nil
+	}
+	err := config.DB.Preload("Skill").Where("task_type_id IN ?", taskTypeIDs).Find(&skills).Error
+	return skills, err
+}
+
+// ReplaceTaskTypeSkills 在一个事务中整体替换任务类型的技能要求
+func ReplaceTaskTypeSkills(taskTypeID uuid.UUID, skills []model.TaskTypeSkill) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_type_id = ?", taskTypeID).Delete(&model.TaskTypeSkill{}).Error; err != nil {
+			return err
+		}
+		if len(skills) == 0 {
+			return nil
+		}
+		return tx.Omit("Skill").Create(&skills).Error
+	})
+}
+
+// ListTaskSkills 获取单个任务的技能要求
+func ListTaskSkills(taskID uint) ([]model.TaskSkill, error) {
+	var skills []model.TaskSkill
+	err := config.DB.Preload("Skill").Where("task_id = ?", taskID).Find(&skills).Error
+	return skills, err
+}
+
+// ListTaskSkillsForTasks 一次性获取多个任务的技能要求
+func ListTaskSkillsForTasks(taskIDs []uint) ([]model.TaskSkill, error) {
+	var skills []model.TaskSkill
+	if len(taskIDs) == 0 {
+		return skills, nil
+	}
+	err := config.DB.Preload("Skill").Where("task_id IN ?", taskIDs).Find(&skills).Error
+	return skills, err
+}
+
+// ReplaceTaskSkills 在一个事务中整体替换单个任务的技能要求
+func ReplaceTaskSkills(taskID uint, skills []model.TaskSkill) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", taskID).Delete(&model.TaskSkill{}).Error; err != nil {
+			return err
+		}
+		if len(skills) == 0 {
+			return nil
+		}
+		return tx.Omit("Skill").Create(&skills).Error
+	})
+}
@@ -4,6 +4,7 @@ package repository
 import (
 	"gotasksys/internal/config"
 	"gotasksys/internal/model"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -20,6 +21,24 @@ func FindTaskByID(id uint) (model.Task, error) {
 	return task, result.Error
 }
 
+// FindTaskIDByKey 根据人类可读的任务编号 (如 'OPS-128') 查找任务ID
+func FindTaskIDByKey(key string) (uint, error) {
+	var task model.Task
+	result := config.DB.Select("id").Where("task_key = ?", key).First(&task)
+	return task.ID, result.Error
+}
+
+// NextTaskKeySequence 原子地获取某个前缀的下一个编号
+func NextTaskKeySequence(prefix string) (int64, error) {
+	var next int64
+	err := config.DB.Raw(`
+		INSERT INTO task_key_sequences (prefix, last_value) VALUES (?, 1)
+		ON CONFLICT (prefix) DO UPDATE SET last_value = task_key_sequences.last_value + 1
+		RETURNING last_value
+	`, prefix).Scan(&next).Error
+	return next, err
+}
+
 func UpdateTask(task *model.Task) error {
 	result := config.DB.Save(task)
 	return result.Error
@@ -111,17 +130,26 @@ type PerformanceMetrics struct {
 	AvgComplexity    float64
 }
 
-// GetPerformanceMetricsForUser 获取一个用户所有已完成任务的各项评价平均分
-func GetPerformanceMetricsForUser(userID uuid.UUID) (PerformanceMetrics, error) {
+// evaluationColumn 返回评价分所在的列：basis 为 'normalized' 时使用校准分，尚未校准的任务回退到原始分
+func evaluationColumn(basis string) string {
+	if basis == "normalized" {
+		return "COALESCE(normalized_evaluation, evaluation)"
+	}
+	return "evaluation"
+}
+
+// GetPerformanceMetricsForUser 获取一个用户所有已完成任务的各项评价平均分，basis 为 'raw' 或 'normalized'
+func GetPerformanceMetricsForUser(userID uuid.UUID, basis string) (PerformanceMetrics, error) {
 	var metrics PerformanceMetrics
 
 	// 我们使用原生SQL查询，因为JSON字段的聚合操作非常复杂，原生SQL更清晰高效
+	column := evaluationColumn(basis)
 	query := `
 		SELECT 
-			COALESCE(AVG((evaluation->>'timeliness')::numeric), 0) as avg_timeliness,
-			COALESCE(AVG((evaluation->>'quality')::numeric), 0) as avg_quality,
-			COALESCE(AVG((evaluation->>'collaboration')::numeric), 0) as avg_collaboration,
-			COALESCE(AVG((evaluation->>'complexity')::numeric), 0) as avg_complexity
+			COALESCE(AVG((` + column + `->>'timeliness')::numeric), 0) as avg_timeliness,
+			COALESCE(AVG((` + column + `->>'quality')::numeric), 0) as avg_quality,
+			COALESCE(AVG((` + column + `->>'collaboration')::numeric), 0) as avg_collaboration,
+			COALESCE(AVG((` + column + `->>'complexity')::numeric), 0) as avg_complexity
 		FROM 
 			tasks
 		WHERE 
@@ -136,6 +164,49 @@ func GetPerformanceMetricsForUser(userID uuid.UUID) (PerformanceMetrics, error)
 	return metrics, nil
 }
 
+// GetPerformanceMetricsForUsers 一次性获取多个用户已完成任务的各项评价平均分，没有评价数据的用户不在结果中
+func GetPerformanceMetricsForUsers(userIDs []uuid.UUID, basis string) (map[uuid.UUID]PerformanceMetrics, error) {
+	result := make(map[uuid.UUID]PerformanceMetrics)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		AssigneeID       uuid.UUID
+		AvgTimeliness    float64
+		AvgQuality       float64
+		AvgCollaboration float64
+		AvgComplexity    float64
+	}
+	column := evaluationColumn(basis)
+	query := `
+		SELECT 
+			assignee_id,
+			COALESCE(AVG((` + column + `->>'timeliness')::numeric), 0) as avg_timeliness,
+			COALESCE(AVG((` + column + `->>'quality')::numeric), 0) as avg_quality,
+			COALESCE(AVG((` + column + `->>'collaboration')::numeric), 0) as avg_collaboration,
+			COALESCE(AVG((` + column + `->>'complexity')::numeric), 0) as avg_complexity
+		FROM 
+			tasks
+		WHERE 
+			assignee_id IN ? AND status = 'completed' AND evaluation IS NOT NULL
+		GROUP BY
+			assignee_id;
+	`
+	if err := config.DB.Raw(query, userIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.AssigneeID] = PerformanceMetrics{
+			AvgTimeliness:    row.AvgTimeliness,
+			AvgQuality:       row.AvgQuality,
+			AvgCollaboration: row.AvgCollaboration,
+			AvgComplexity:    row.AvgComplexity,
+		}
+	}
+	return result, nil
+}
+
 // BatchUpdateSubtasksAssignee 批量更新一个主任务下，特定原负责人的所有子任务的新负责人
 func BatchUpdateSubtasksAssignee(parentTaskID uint, oldAssigneeID, newAssigneeID uuid.UUID) error {
 	result := config.DB.Model(&model.Task{}).
@@ -144,3 +215,132 @@ func BatchUpdateSubtasksAssignee(parentTaskID uint, oldAssigneeID, newAssigneeID
 
 	return result.Error
 }
+
+// TeamPoolDemand 某个团队提出、仍在任务池中等待领取的任务需求
+type TeamPoolDemand struct {
+	Team        string
+	TaskCount   int64
+	EffortHours float64
+}
+
+// SummarizePoolDemandByTeam 按创建人所在团队统计任务池中的任务数量和工时
+func SummarizePoolDemandByTeam() ([]TeamPoolDemand, error) {
+	var demands []TeamPoolDemand
+	query := `
+		SELECT
+			COALESCE(users.team, '') AS team,
+			COUNT(*) AS task_count,
+			COALESCE(SUM(tasks.effort), 0) AS effort_hours
+		FROM
+			tasks
+			LEFT JOIN users ON users.id = tasks.creator_id
+		WHERE
+			tasks.status = 'in_pool'
+		GROUP BY
+			COALESCE(users.team, '');
+	`
+	result := config.DB.Raw(query).Scan(&demands)
+	return demands, result.Error
+}
+
+// TaskTypePerformance 某个用户在某种任务类型上的历史完成情况
+type TaskTypePerformance struct {
+	AssigneeID        uuid.UUID
+	CompletedCount    int64
+	AvgCompositeScore float64
+}
+
+// GetTaskTypePerformanceForUsers 一次性获取多个用户在指定任务类型上已评价任务的数量和平均综合分
+func GetTaskTypePerformanceForUsers(taskTypeID uuid.UUID, userIDs []uuid.UUID) (map[uuid.UUID]TaskTypePerformance, error) {
+	result := make(map[uuid.UUID]TaskTypePerformance)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var rows []TaskTypePerformance
+	query := `
+		SELECT
+			assignee_id,
+			COUNT(*) AS completed_count,
+			COALESCE(AVG((evaluation->>'composite_score')::numeric), 0) AS avg_composite_score
+		FROM
+			tasks
+		WHERE
+			assignee_id IN ? AND task_type_id = ? AND status = 'completed' AND evaluation IS NOT NULL
+		GROUP BY
+			assignee_id;
+	`
+	if err := config.DB.Raw(query, userIDs, taskTypeID).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.AssigneeID] = row
+	}
+	return result, nil
+}
+
+// DifficultyExperience 某个用户已完成任务的技术难度分布
+type DifficultyExperience struct {
+	AssigneeID    uuid.UUID
+	RatedCount    int64
+	AvgDifficulty float64
+	MaxDifficulty float64
+}
+
+// GetDifficultyExperienceForUsers 一次性获取多个用户已完成任务的平均和最高技术难度综合分
+func GetDifficultyExperienceForUsers(userIDs []uuid.UUID) (map[uuid.UUID]DifficultyExperience, error) {
+	result := make(map[uuid.UUID]DifficultyExperience)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	var rows []DifficultyExperience
+	query := `
+		SELECT
+			assignee_id,
+			COUNT(*) AS rated_count,
+			COALESCE(AVG((difficulty_rating->>'composite_difficulty_score')::numeric), 0) AS avg_difficulty,
+			COALESCE(MAX((difficulty_rating->>'composite_difficulty_score')::numeric), 0) AS max_difficulty
+		FROM
+			tasks
+		WHERE
+			assignee_id IN ? AND status = 'completed' AND difficulty_rating->>'composite_difficulty_score' IS NOT NULL
+		GROUP BY
+			assignee_id;
+	`
+	if err := config.DB.Raw(query, userIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.AssigneeID] = row
+	}
+	return result, nil
+}
+
+// AssignPoolTaskIfUnclaimed 仅当任务仍在任务池且无人负责时将其交给某人 (领取、经理指派或自动指派)，返回是否成功
+// 条件更新保证同时发生的领取、指派和自动指派不会互相覆盖；updates 中可附带额外字段 (如指派的经理)
+func AssignPoolTaskIfUnclaimed(taskID uint, assigneeID uuid.UUID, updates map[string]interface{}) (bool, error) {
+	fields := map[string]interface{}{
+		"status":      "in_progress",
+		"assignee_id": assigneeID,
+		"claimed_at":  time.Now(),
+	}
+	for key, value := range updates {
+		fields[key] = value
+	}
+	result := config.DB.Model(&model.Task{}).
+		Where("id = ? AND status = 'in_pool' AND assignee_id IS NULL", taskID).
+		Updates(fields)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListEvaluatedTasksInRange 获取指定用户在 [start, end) 期间完成并已评价的任务 (只加载绩效计算需要的字段)
+func ListEvaluatedTasksInRange(userIDs []uuid.UUID, start, end time.Time) ([]model.Task, error) {
+	var tasks []model.Task
+	if len(userIDs) == 0 {
+		return tasks, nil
+	}
+	err := config.DB.Select("id", "assignee_id", "task_type_id", "effort", "evaluation", "normalized_evaluation", "difficulty_rating", "completed_at").
+		Where("assignee_id IN ? AND status = 'completed' AND evaluation IS NOT NULL", userIDs).
+		Where("completed_at >= ? AND completed_at < ?", start, end).
+		Find(&tasks).Error
+	return tasks, err
+}
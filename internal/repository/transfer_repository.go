@@ -4,12 +4,33 @@ package repository
 import (
 	"gotasksys/internal/config"
 	"gotasksys/internal/model"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-func CreateTransfer(transfer *model.TaskTransfer) error {
-	return config.DB.Create(transfer).Error
+// CreateTransferForTask 在一个事务中，仅当任务仍处于进行中且负责人仍是发起人时更新任务 (taskUpdates)，并创建转交记录
+// 任务状态或负责人已被其他请求改变 (例如并发发起的另一次转交) 时不做任何修改，返回 false
+func CreateTransferForTask(transfer *model.TaskTransfer, taskUpdates map[string]interface{}) (bool, error) {
+	created := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Task{}).
+			Where("id = ? AND status = ? AND assignee_id = ?", transfer.TaskID, "in_progress", transfer.FromUserID).
+			Updates(taskUpdates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		created = true
+		return nil
+	})
+	return created, err
 }
 
 func FindTransferByID(id uuid.UUID) (model.TaskTransfer, error) {
@@ -18,6 +39,72 @@ func FindTransferByID(id uuid.UUID) (model.TaskTransfer, error) {
 	return transfer, err
 }
 
-func UpdateTransferStatus(id uuid.UUID, status string) error {
-	return config.DB.Model(&model.TaskTransfer{}).Where("id = ?", id).Update("status", status).Error
+// TransitionTransfer 在一个事务中，仅当转交仍处于 fromStatuses 之一时更新转交记录，并同时更新关联任务 (taskUpdates 为 nil 时不更新任务)
+// afterUpdate 不为 nil 时在同一事务中执行 (例如入队后续作业)。转交状态已被其他请求改变时不做任何修改，返回 false
+func TransitionTransfer(id uuid.UUID, fromStatuses []string, transferUpdates map[string]interface{}, taskID uint, taskUpdates map[string]interface{}, afterUpdate func(tx *gorm.DB) error) (bool, error) {
+	changed := false
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TaskTransfer{}).Where("id = ? AND status IN ?", id, fromStatuses).Updates(transferUpdates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+		if taskUpdates != nil {
+			if err := tx.Model(&model.Task{}).Where("id = ?", taskID).Updates(taskUpdates).Error; err != nil {
+				return err
+			}
+		}
+		if afterUpdate != nil {
+			if err := afterUpdate(tx); err != nil {
+				return err
+			}
+		}
+		changed = true
+		return nil
+	})
+	return changed, err
+}
+
+// ListIncomingTransfers 获取发给某个用户的转交请求 (收件箱)，附带任务与发起人信息
+// status 为空时返回全部状态
+func ListIncomingTransfers(userID uuid.UUID, status string) ([]model.TaskTransfer, error) {
+	var transfers []model.TaskTransfer
+	db := config.DB.Preload("Task").Preload("FromUser").Preload("ToUser").Where("to_user_id = ?", userID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Order("created_at desc").Find(&transfers).Error
+	return transfers, err
+}
+
+// ListOutgoingTransfers 获取某个用户发起的转交请求 (发件箱)，附带任务与接收人信息
+// status 为空时返回全部状态
+func ListOutgoingTransfers(userID uuid.UUID, status string) ([]model.TaskTransfer, error) {
+	var transfers []model.TaskTransfer
+	db := config.DB.Preload("Task").Preload("FromUser").Preload("ToUser").Where("from_user_id = ?", userID)
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Order("created_at desc").Find(&transfers).Error
+	return transfers, err
+}
+
+// ListPendingTransfersIdleSince 获取最近一次状态变化早于指定时间、且仍处于待处理(含待审批)状态的转交请求
+// 经理审批通过的转交从审批时刻 (decided_at) 重新计时，其余从创建时刻计时
+func ListPendingTransfersIdleSince(before time.Time) ([]model.TaskTransfer, error) {
+	var transfers []model.TaskTransfer
+	err := config.DB.Where("status IN (?) AND COALESCE(decided_at, created_at) < ?", []string{"pending", "pending_approval"}, before).Find(&transfers).Error
+	return transfers, err
+}
+
+// ListTransfersAwaitingApproval 获取所有等待经理审批的转交请求
+func ListTransfersAwaitingApproval() ([]model.TaskTransfer, error) {
+	var transfers []model.TaskTransfer
+	err := config.DB.Preload("Task").Preload("FromUser").Preload("ToUser").
+		Where("status = ?", "pending_approval").
+		Order("created_at asc").
+		Find(&transfers).Error
+	return transfers, err
 }
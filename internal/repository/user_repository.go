@@ -38,6 +38,13 @@ func FindAllActiveMembers() ([]model.User, error) {
 	return members, result.Error
 }
 
+// FindUsersByRole 获取指定角色的所有用户
+func FindUsersByRole(role string) ([]model.User, error) {
+	var users []model.User
+	err := config.DB.Where("role = ?", role).Find(&users).Error
+	return users, err
+}
+
 // CreateUser 创建一个新用户
 func CreateUser(user *model.User) error {
 	result := config.DB.Create(user)
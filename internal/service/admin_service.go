@@ -15,12 +15,17 @@ func ListTaskTypesService() ([]model.TaskType, error) {
 	return repository.ListTaskTypes()
 }
 
-func CreateTaskTypeService(name string) (model.TaskType, error) {
+func CreateTaskTypeService(name string, keyPrefix *string) (model.TaskType, error) {
+	keyPrefix, err := normalizeTaskKeyPrefix(keyPrefix)
+	if err != nil {
+		return model.TaskType{}, err
+	}
 	taskType := model.TaskType{
 		Name:      name,
+		KeyPrefix: keyPrefix,
 		IsEnabled: true,
 	}
-	err := repository.CreateTaskType(&taskType)
+	err = repository.CreateTaskType(&taskType)
 	return taskType, err
 }
 
@@ -91,8 +96,19 @@ func UpdateUserByAdminService(userID uuid.UUID, input model.User) error {
 }
 
 // UpdateTaskTypeService 封装了更新任务类型的业务逻辑
-func UpdateTaskTypeService(id uuid.UUID, name string, isEnabled bool) error {
+// keyPrefix 为 nil 时不修改前缀，为空字符串时清除前缀
+// 修改任务编号前缀只影响之后生成的编号，已有任务的编号保持不变
+func UpdateTaskTypeService(id uuid.UUID, name string, keyPrefix *string, isEnabled bool) error {
 	// 此处可添加更多业务逻辑，如名称是否重复等
+	if keyPrefix != nil {
+		normalized, err := normalizeTaskKeyPrefix(keyPrefix)
+		if err != nil {
+			return err
+		}
+		if err := repository.UpdateTaskTypeKeyPrefix(id, normalized); err != nil {
+			return err
+		}
+	}
 	return repository.UpdateTaskType(id, name, isEnabled)
 }
 
@@ -0,0 +1,197 @@
+// internal/service/approval_service.go
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+	"log"
+	"math"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ApprovalOutcome 描述了一次批准操作之后，任务的多级审批进度
+type ApprovalOutcome struct {
+	Approved          bool   `json:"approved"` // 是否已满足审批策略并进入任务池
+	PolicyName        string `json:"policy_name,omitempty"`
+	ApprovalsReceived int    `json:"approvals_received"`
+	ApprovalsRequired int    `json:"approvals_required"`
+	RequiredRole      string `json:"required_role,omitempty"`
+	RoleSatisfied     bool   `json:"role_satisfied"`
+}
+
+// mergedApproval 合并多位审批人意见后的最终结果
+type mergedApproval struct {
+	Effort           int
+	Priority         string
+	TaskTypeID       *uuid.UUID
+	DifficultyRating datatypes.JSON
+}
+
+// difficultyDimensions 技术难度评分的四个维度
+var difficultyDimensions = []string{"novelty", "logic_complexity", "impact_scope", "collaboration_cost"}
+
+// evaluateApprovalPolicy 根据本轮的批准记录，判断任务是否满足审批策略
+// 匹配策略时，使用审批人建议的最大工时和最近一次审批确认的任务类型；命中多条策略时取要求最严格的一条
+func evaluateApprovalPolicy(approvals []model.TaskApproval) (ApprovalOutcome, error) {
+	policies, err := repository.ListActiveApprovalPolicies()
+	if err != nil {
+		return ApprovalOutcome{}, err
+	}
+
+	maxEffort := 0
+	for _, approval := range approvals {
+		if approval.Effort > maxEffort {
+			maxEffort = approval.Effort
+		}
+	}
+	taskTypeID := approvals[len(approvals)-1].TaskTypeID
+
+	// 默认策略：单人审批即可
+	outcome := ApprovalOutcome{ApprovalsRequired: 1}
+	for _, policy := range policies {
+		if !approvalPolicyMatches(policy, maxEffort, taskTypeID) {
+			continue
+		}
+		stricter := policy.RequiredApprovals > outcome.ApprovalsRequired ||
+			(policy.RequiredApprovals == outcome.ApprovalsRequired && outcome.RequiredRole == "" && policy.RequiredRole != "")
+		if outcome.PolicyName == "" || stricter {
+			outcome.PolicyName = policy.Name
+			outcome.ApprovalsRequired = policy.RequiredApprovals
+			outcome.RequiredRole = policy.RequiredRole
+		}
+	}
+
+	outcome.ApprovalsReceived = len(approvals)
+	outcome.RoleSatisfied = outcome.RequiredRole == ""
+	for _, approval := range approvals {
+		if approval.ApproverRole == outcome.RequiredRole {
+			outcome.RoleSatisfied = true
+		}
+	}
+	outcome.Approved = outcome.ApprovalsReceived >= outcome.ApprovalsRequired && outcome.RoleSatisfied
+	return outcome, nil
+}
+
+// approvalPolicyMatches 判断一条策略是否适用于给定的工时和任务类型
+func approvalPolicyMatches(policy model.ApprovalPolicy, effort int, taskTypeID *uuid.UUID) bool {
+	if policy.MinEffort == nil && policy.TaskTypeID == nil {
+		return false
+	}
+	if policy.MinEffort != nil && effort < *policy.MinEffort {
+		return false
+	}
+	if policy.TaskTypeID != nil && (taskTypeID == nil || *policy.TaskTypeID != *taskTypeID) {
+		return false
+	}
+	return true
+}
+
+// mergeApprovals 合并多位审批人的意见：
+// 工时取所有建议的平均值 (四舍五入)，优先级和任务类型以最后一位审批人为准，难度评分按维度取平均
+func mergeApprovals(approvals []model.TaskApproval) mergedApproval {
+	last := approvals[len(approvals)-1]
+	merged := mergedApproval{Priority: last.Priority, TaskTypeID: last.TaskTypeID}
+
+	var effortSum int
+	ratingSums := make(map[string]float64)
+	ratingCount := 0
+	for _, approval := range approvals {
+		effortSum += approval.Effort
+
+		if len(approval.DifficultyRating) == 0 {
+			continue
+		}
+		var rating map[string]float64
+		if err := json.Unmarshal(approval.DifficultyRating, &rating); err != nil {
+			log.Printf("Skipping malformed difficulty rating in approval %s: %v", approval.ID, err)
+			continue
+		}
+		for _, dimension := range difficultyDimensions {
+			ratingSums[dimension] += rating[dimension]
+		}
+		ratingCount++
+	}
+	merged.Effort = int(math.Round(float64(effortSum) / float64(len(approvals))))
+
+	if ratingCount > 0 {
+		averaged := make(map[string]float64)
+		for _, dimension := range difficultyDimensions {
+			averaged[dimension] = ratingSums[dimension] / float64(ratingCount)
+		}
+		if ratingBytes, err := json.Marshal(withCompositeDifficultyScore(averaged)); err == nil {
+			merged.DifficultyRating = ratingBytes
+		}
+	}
+	return merged
+}
+
+// withCompositeDifficultyScore 根据四个维度计算技术难度综合分，写入 'composite_difficulty_score'
+func withCompositeDifficultyScore(rating map[string]float64) map[string]float64 {
+	// 我们可以在这里增加校验，确保4个维度都存在
+	var sum float64
+	for _, dimension := range difficultyDimensions {
+		sum += rating[dimension]
+	}
+	rating["composite_difficulty_score"] = sum / float64(len(difficultyDimensions))
+	return rating
+}
+
+// ListTaskApprovalsService 获取一个任务的全部审批历史
+func ListTaskApprovalsService(taskID uint) ([]model.TaskApproval, error) {
+	return repository.ListTaskApprovals(taskID)
+}
+
+// --- 审批策略管理 ---
+
+func ListApprovalPoliciesService() ([]model.ApprovalPolicy, error) {
+	return repository.ListApprovalPolicies()
+}
+
+func CreateApprovalPolicyService(input model.ApprovalPolicy) (model.ApprovalPolicy, error) {
+	if err := validateApprovalPolicy(input); err != nil {
+		return model.ApprovalPolicy{}, err
+	}
+	err := repository.CreateApprovalPolicy(&input)
+	return input, err
+}
+
+func UpdateApprovalPolicyService(id uuid.UUID, input model.ApprovalPolicy) (model.ApprovalPolicy, error) {
+	if err := validateApprovalPolicy(input); err != nil {
+		return model.ApprovalPolicy{}, err
+	}
+	policy, err := repository.FindApprovalPolicyByID(id)
+	if err != nil {
+		return model.ApprovalPolicy{}, errors.New("approval policy not found")
+	}
+	policy.Name = input.Name
+	policy.MinEffort = input.MinEffort
+	policy.TaskTypeID = input.TaskTypeID
+	policy.RequiredApprovals = input.RequiredApprovals
+	policy.RequiredRole = input.RequiredRole
+	policy.IsActive = input.IsActive
+
+	err = repository.UpdateApprovalPolicy(&policy)
+	return policy, err
+}
+
+func DeleteApprovalPolicyService(id uuid.UUID) error {
+	return repository.DeleteApprovalPolicy(id)
+}
+
+// validateApprovalPolicy 校验审批策略的输入
+func validateApprovalPolicy(policy model.ApprovalPolicy) error {
+	if policy.MinEffort == nil && policy.TaskTypeID == nil {
+		return errors.New("approval policy must specify a minimum effort or a task type")
+	}
+	if policy.RequiredApprovals < 1 {
+		return errors.New("required approvals must be at least 1")
+	}
+	if policy.RequiredRole != "" && policy.RequiredRole != "manager" && policy.RequiredRole != "system_admin" {
+		return errors.New("required role must be 'manager' or 'system_admin'")
+	}
+	return nil
+}
@@ -0,0 +1,413 @@
+// internal/service/calibration_service.go
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	calibrationMethodZScore     = "zscore"
+	calibrationMethodPercentile = "percentile"
+)
+
+// calibrationDimensions 参与校准的评价维度 (四个维度及综合分)
+var calibrationDimensions = append(append([]string{}, evaluationDimensions...), "composite_score")
+
+// calibrationConfigKeys 修改后需要重新校准所有评价的配置项
+var calibrationConfigKeys = map[string]bool{
+	"evaluation_normalization_method":      true,
+	"evaluation_normalization_min_samples": true,
+	"evaluation_calibration_window_days":   true,
+}
+
+// ScoreStats 一组评价分的统计量
+type ScoreStats struct {
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+	StdDev   float64 `json:"std_dev"`
+}
+
+// ScoreBucket 综合分分布中的一档 (综合分四舍五入到整数)
+type ScoreBucket struct {
+	Score int     `json:"score"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// ReviewerCalibration 一位评价人的打分习惯及与其他评价人的对比
+type ReviewerCalibration struct {
+	Reviewer         model.User            `json:"reviewer"`
+	SampleSize       int                   `json:"sample_size"`
+	Calibrated       bool                  `json:"calibrated"` // 样本数达到最低要求，其评分会被校准
+	Composite        ScoreStats            `json:"composite"`
+	Dimensions       map[string]ScoreStats `json:"dimensions"`
+	Distribution     []ScoreBucket         `json:"distribution"`
+	PeerSampleSize   int                   `json:"peer_sample_size"`
+	PeerComposite    ScoreStats            `json:"peer_composite"` // 其他评价人打出的综合分
+	PeerDistribution []ScoreBucket         `json:"peer_distribution"`
+	MeanDelta        float64               `json:"mean_delta"` // 与其他评价人平均分的差，正数表示偏宽松
+	Leniency         float64               `json:"leniency"`   // MeanDelta 除以其他评价人的标准差
+}
+
+// CalibrationReport 评价人校准分析结果
+type CalibrationReport struct {
+	Method     string                `json:"method"`
+	MinSamples int                   `json:"min_samples"`
+	WindowDays int                   `json:"window_days"`
+	SampleSize int                   `json:"sample_size"`
+	Population ScoreStats            `json:"population"`
+	Reviewers  []ReviewerCalibration `json:"reviewers"`
+}
+
+// scoreSample 一组评价分及其统计量，sorted 用于百分位计算
+type scoreSample struct {
+	sorted []float64
+	mean   float64
+	std    float64
+}
+
+func newScoreSample(values []float64) scoreSample {
+	sample := scoreSample{sorted: append([]float64{}, values...)}
+	sort.Float64s(sample.sorted)
+	if len(values) == 0 {
+		return sample
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	sample.mean = sum / float64(len(values))
+	var squares float64
+	for _, v := range values {
+		squares += (v - sample.mean) * (v - sample.mean)
+	}
+	sample.std = math.Sqrt(squares / float64(len(values)))
+	return sample
+}
+
+func (s scoreSample) stats() ScoreStats {
+	return ScoreStats{Mean: roundScore(s.mean), Variance: roundScore(s.std * s.std), StdDev: roundScore(s.std)}
+}
+
+// percentileRank 计算 value 在样本中的百分位 (0-1)，相同分数取中间位置
+func (s scoreSample) percentileRank(value float64) float64 {
+	below := sort.SearchFloat64s(s.sorted, value)
+	equal := sort.SearchFloat64s(s.sorted, math.Nextafter(value, math.Inf(1))) - below
+	return (float64(below) + float64(equal)/2) / float64(len(s.sorted))
+}
+
+// quantile 计算样本在百分位 p (0-1) 处的值 (线性插值)
+func (s scoreSample) quantile(p float64) float64 {
+	n := len(s.sorted)
+	if n == 0 {
+		return 0
+	}
+	pos := p * float64(n-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return s.sorted[lower] + (s.sorted[upper]-s.sorted[lower])*(pos-float64(lower))
+}
+
+// calibrationModel 全体评价和每位评价人在各维度上的分数样本
+type calibrationModel struct {
+	population map[string]scoreSample
+	reviewers  map[uuid.UUID]map[string]scoreSample
+	counts     map[uuid.UUID]int
+}
+
+func buildCalibrationModel(tasks []model.Task) calibrationModel {
+	populationValues := make(map[string][]float64)
+	reviewerValues := make(map[uuid.UUID]map[string][]float64)
+	for _, task := range tasks {
+		scores, ok := taskEvaluationScores(task)
+		if !ok || task.EvaluatorID == nil {
+			continue
+		}
+		reviewer := *task.EvaluatorID
+		if reviewerValues[reviewer] == nil {
+			reviewerValues[reviewer] = make(map[string][]float64)
+		}
+		for _, dimension := range calibrationDimensions {
+			populationValues[dimension] = append(populationValues[dimension], scores[dimension])
+			reviewerValues[reviewer][dimension] = append(reviewerValues[reviewer][dimension], scores[dimension])
+		}
+	}
+
+	m := calibrationModel{
+		population: make(map[string]scoreSample),
+		reviewers:  make(map[uuid.UUID]map[string]scoreSample),
+		counts:     make(map[uuid.UUID]int),
+	}
+	for dimension, values := range populationValues {
+		m.population[dimension] = newScoreSample(values)
+	}
+	for reviewer, byDimension := range reviewerValues {
+		m.reviewers[reviewer] = make(map[string]scoreSample)
+		for dimension, values := range byDimension {
+			m.reviewers[reviewer][dimension] = newScoreSample(values)
+		}
+		m.counts[reviewer] = len(byDimension["composite_score"])
+	}
+	return m
+}
+
+// normalizeScore 将评价人打出的分数换算到全体评价的分布上
+// zscore: 按评价人的均值和标准差求 z 值，再换算为全体的均值 + z * 标准差 (限制在全体的最低分和最高分之间)
+// percentile: 求分数在该评价人所有评分中的百分位，再取全体评价在该百分位处的分数
+func normalizeScore(value float64, reviewer, population scoreSample, method string) float64 {
+	if method == calibrationMethodPercentile {
+		return population.quantile(reviewer.percentileRank(value))
+	}
+	z := 0.0
+	if reviewer.std > 0 {
+		z = (value - reviewer.mean) / reviewer.std
+	}
+	low, high := population.sorted[0], population.sorted[len(population.sorted)-1]
+	return math.Min(math.Max(population.mean+z*population.std, low), high)
+}
+
+// normalizedEvaluation 计算单个任务的校准评价，评价人样本不足时校准分等于原始分
+func (m calibrationModel) normalizedEvaluation(task model.Task, method string, minSamples int) (datatypes.JSON, bool) {
+	scores, ok := taskEvaluationScores(task)
+	if !ok || task.EvaluatorID == nil {
+		return nil, false
+	}
+	reviewer := *task.EvaluatorID
+	calibrated := m.counts[reviewer] >= minSamples
+
+	normalized := map[string]interface{}{
+		"method":           method,
+		"calibrated":       calibrated,
+		"reviewer_samples": m.counts[reviewer],
+	}
+	var sum float64
+	for _, dimension := range evaluationDimensions {
+		value := scores[dimension]
+		if calibrated {
+			value = roundScore(normalizeScore(value, m.reviewers[reviewer][dimension], m.population[dimension], method))
+		}
+		normalized[dimension] = value
+		sum += value
+	}
+	// 与原始评价一致，综合分为四个维度的平均值
+	if calibrated {
+		normalized["composite_score"] = roundScore(sum / float64(len(evaluationDimensions)))
+	} else {
+		normalized["composite_score"] = scores["composite_score"]
+	}
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, false
+	}
+	return datatypes.JSON(data), true
+}
+
+// loadCalibrationSettings 读取校准相关的系统配置
+func loadCalibrationSettings() (method string, minSamples int, windowDays int) {
+	method = calibrationMethodZScore
+	if value, err := repository.GetSystemConfigValueByKey("evaluation_normalization_method"); err == nil && value == calibrationMethodPercentile {
+		method = calibrationMethodPercentile
+	}
+	return method, getConfigInt("evaluation_normalization_min_samples", 5), getConfigInt("evaluation_calibration_window_days", 365)
+}
+
+// loadCalibrationTasks 获取校准窗口内的评价，windowDays 为 0 时取全部历史评价
+func loadCalibrationTasks(windowDays int) ([]model.Task, error) {
+	if windowDays <= 0 {
+		return repository.ListEvaluationsForCalibration(nil)
+	}
+	since := time.Now().AddDate(0, 0, -windowDays)
+	return repository.ListEvaluationsForCalibration(&since)
+}
+
+// defaultScoreBasis 绩效统计默认使用的评价分 ('raw' 或 'normalized')
+func defaultScoreBasis() string {
+	if value, err := repository.GetSystemConfigValueByKey("performance_score_basis"); err == nil && value == "normalized" {
+		return "normalized"
+	}
+	return "raw"
+}
+
+// --- 校准分析 ---
+
+// GetReviewerCalibrationService 统计每位评价人的打分均值、方差和分布，并与其他评价人对比
+// windowDays 小于 0 时使用系统配置 evaluation_calibration_window_days
+func GetReviewerCalibrationService(windowDays int) (CalibrationReport, error) {
+	method, minSamples, configuredDays := loadCalibrationSettings()
+	if windowDays < 0 {
+		windowDays = configuredDays
+	}
+	tasks, err := loadCalibrationTasks(windowDays)
+	if err != nil {
+		return CalibrationReport{}, err
+	}
+
+	compositeByReviewer := make(map[uuid.UUID][]float64)
+	var allComposites []float64
+	for _, task := range tasks {
+		if scores, ok := taskEvaluationScores(task); ok {
+			compositeByReviewer[*task.EvaluatorID] = append(compositeByReviewer[*task.EvaluatorID], scores["composite_score"])
+			allComposites = append(allComposites, scores["composite_score"])
+		}
+	}
+	m := buildCalibrationModel(tasks)
+
+	report := CalibrationReport{
+		Method:     method,
+		MinSamples: minSamples,
+		WindowDays: windowDays,
+		SampleSize: len(allComposites),
+		Population: newScoreSample(allComposites).stats(),
+		Reviewers:  []ReviewerCalibration{},
+	}
+	if len(compositeByReviewer) == 0 {
+		return report, nil
+	}
+
+	reviewerIDs := make([]uuid.UUID, 0, len(compositeByReviewer))
+	for id := range compositeByReviewer {
+		reviewerIDs = append(reviewerIDs, id)
+	}
+	users, err := repository.FindUsersByIDs(reviewerIDs)
+	if err != nil {
+		return CalibrationReport{}, err
+	}
+	usersByID := make(map[uuid.UUID]model.User, len(users))
+	for _, u := range users {
+		usersByID[u.ID] = u
+	}
+	buckets := scoreBucketKeys(allComposites)
+
+	for _, reviewerID := range reviewerIDs {
+		own := compositeByReviewer[reviewerID]
+		var peers []float64
+		for otherID, values := range compositeByReviewer {
+			if otherID != reviewerID {
+				peers = append(peers, values...)
+			}
+		}
+		ownSample, peerSample := newScoreSample(own), newScoreSample(peers)
+
+		calibration := ReviewerCalibration{
+			Reviewer:         usersByID[reviewerID],
+			SampleSize:       len(own),
+			Calibrated:       len(own) >= minSamples,
+			Composite:        ownSample.stats(),
+			Dimensions:       make(map[string]ScoreStats),
+			Distribution:     scoreDistribution(own, buckets),
+			PeerSampleSize:   len(peers),
+			PeerComposite:    peerSample.stats(),
+			PeerDistribution: scoreDistribution(peers, buckets),
+		}
+		for _, dimension := range evaluationDimensions {
+			calibration.Dimensions[dimension] = m.reviewers[reviewerID][dimension].stats()
+		}
+		if len(peers) > 0 {
+			calibration.MeanDelta = roundScore(ownSample.mean - peerSample.mean)
+			if peerSample.std > 0 {
+				calibration.Leniency = roundScore((ownSample.mean - peerSample.mean) / peerSample.std)
+			}
+		}
+		report.Reviewers = append(report.Reviewers, calibration)
+	}
+
+	// 最宽松的评价人排在最前
+	sort.Slice(report.Reviewers, func(i, j int) bool {
+		if report.Reviewers[i].MeanDelta != report.Reviewers[j].MeanDelta {
+			return report.Reviewers[i].MeanDelta > report.Reviewers[j].MeanDelta
+		}
+		return report.Reviewers[i].Reviewer.RealName < report.Reviewers[j].Reviewer.RealName
+	})
+	return report, nil
+}
+
+// scoreBucketKeys 所有出现过的整数分档，评价人和对比组使用同一组分档
+func scoreBucketKeys(values []float64) []int {
+	seen := make(map[int]bool)
+	var keys []int
+	for _, v := range values {
+		key := int(math.Round(v))
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func scoreDistribution(values []float64, keys []int) []ScoreBucket {
+	counts := make(map[int]int)
+	for _, v := range values {
+		counts[int(math.Round(v))]++
+	}
+	buckets := make([]ScoreBucket, 0, len(keys))
+	for _, key := range keys {
+		bucket := ScoreBucket{Score: key, Count: counts[key]}
+		if len(values) > 0 {
+			bucket.Share = roundScore(float64(counts[key]) / float64(len(values)))
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// --- 重新校准 ---
+
+// JobTypeRecalibrateEvaluations 重新计算校准窗口内所有评价的校准分
+const JobTypeRecalibrateEvaluations = "recalibrate_evaluations"
+
+func init() {
+	RegisterJobHandler(JobTypeRecalibrateEvaluations, func(payload json.RawMessage) error {
+		_, err := RecalibrateEvaluationsService()
+		return err
+	})
+}
+
+// scheduleRecalibration 新的评价或校准配置变化后，将重新校准放入作业队列 (已有待执行的校准作业时合并为一次)
+func scheduleRecalibration() {
+	if err := EnqueueCoalescedJob(JobTypeRecalibrateEvaluations, struct{}{}); err != nil {
+		log.Printf("Failed to enqueue evaluation recalibration: %v", err)
+	}
+}
+
+// RecalibrateEvaluationsService 按评价人在校准窗口内的打分习惯，重新计算并保存窗口内每个评价的校准分
+// 窗口之外的任务保留上一次计算的校准分，返回更新的任务数
+func RecalibrateEvaluationsService() (int, error) {
+	method, minSamples, windowDays := loadCalibrationSettings()
+	tasks, err := loadCalibrationTasks(windowDays)
+	if err != nil {
+		return 0, err
+	}
+	m := buildCalibrationModel(tasks)
+
+	values := make(map[uint]datatypes.JSON, len(tasks))
+	for _, task := range tasks {
+		if normalized, ok := m.normalizedEvaluation(task, method, minSamples); ok {
+			values[task.ID] = normalized
+		}
+	}
+	if err := repository.UpdateNormalizedEvaluations(values); err != nil {
+		return 0, err
+	}
+	return len(values), nil
+}
+
+// RequestRecalibrationService 手动触发重新校准 (异步执行)
+func RequestRecalibrationService() error {
+	if err := EnqueueCoalescedJob(JobTypeRecalibrateEvaluations, struct{}{}); err != nil {
+		return errors.New("failed to enqueue recalibration")
+	}
+	return nil
+}
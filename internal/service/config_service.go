@@ -6,21 +6,239 @@ import (
 	"gotasksys/internal/model"
 	"gotasksys/internal/repository"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // --- 系统配置相关 ---
 
+// validUserRoles 系统中所有合法的用户角色
+var validUserRoles = map[string]bool{"system_admin": true, "manager": true, "executor": true, "creator": true}
+
+func ListSystemConfigsService() ([]model.SystemConfig, error) {
+	return repository.ListSystemConfigs()
+}
+
 func UpdateSystemConfigService(key, value string) error {
 	if key == "global_daily_work_hours" {
 		if _, err := strconv.ParseFloat(value, 64); err != nil {
 			return errors.New("invalid value for daily work hours, must be a number")
 		}
 	}
+	if key == "transfer_expiry_hours" {
+		if _, err := strconv.Atoi(value); err != nil {
+			return errors.New("invalid value for transfer expiry hours, must be an integer")
+		}
+	}
+	if key == "transfer_cross_team_requires_approval" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("invalid value for cross-team approval policy, must be true or false")
+		}
+	}
+	if key == "transfer_overload_threshold_percentage" {
+		if threshold, err := strconv.ParseFloat(value, 64); err != nil || threshold < 0 {
+			return errors.New("invalid value for overload threshold, must be a non-negative number")
+		}
+	}
+	if key == "transfer_eligible_roles" {
+		for _, role := range splitConfigList(value) {
+			if !validUserRoles[role] {
+				return errors.New("invalid role in transfer eligible roles: " + role)
+			}
+		}
+	}
+	if key == "personnel_status_cache_seconds" {
+		if seconds, err := strconv.Atoi(value); err != nil || seconds < 0 {
+			return errors.New("invalid value for personnel status cache seconds, must be a non-negative integer")
+		}
+	}
+	if key == "pool_auto_assign_enabled" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("invalid value for pool auto-assign, must be true or false")
+		}
+	}
+	if key == "pool_auto_assign_min_score" {
+		if score, err := strconv.ParseFloat(value, 64); err != nil || score < 0 || score > 100 {
+			return errors.New("invalid value for auto-assign minimum score, must be a number between 0 and 100")
+		}
+	}
+	if key == "skill_match_mode" {
+		if value != "off" && value != "sort" && value != "hide" {
+			return errors.New("invalid value for skill match mode, must be 'off', 'sort' or 'hide'")
+		}
+	}
+	if key == "performance_min_sample_size" {
+		if size, err := strconv.Atoi(value); err != nil || size < 1 {
+			return errors.New("invalid value for performance minimum sample size, must be a positive integer")
+		}
+	}
+	if key == "evaluation_normalization_method" {
+		if value != calibrationMethodZScore && value != calibrationMethodPercentile {
+			return errors.New("invalid value for evaluation normalization method, must be 'zscore' or 'percentile'")
+		}
+	}
+	if key == "evaluation_normalization_min_samples" {
+		if size, err := strconv.Atoi(value); err != nil || size < 1 {
+			return errors.New("invalid value for evaluation normalization minimum samples, must be a positive integer")
+		}
+	}
+	if key == "evaluation_calibration_window_days" {
+		if days, err := strconv.Atoi(value); err != nil || days < 0 {
+			return errors.New("invalid value for evaluation calibration window, must be a non-negative integer")
+		}
+	}
+	if key == "performance_score_basis" {
+		if value != "raw" && value != "normalized" {
+			return errors.New("invalid value for performance score basis, must be 'raw' or 'normalized'")
+		}
+	}
+	if key == "default_task_key_prefix" {
+		prefix, err := normalizeTaskKeyPrefix(&value)
+		if err != nil {
+			return err
+		}
+		if prefix == nil {
+			return errors.New("invalid value for default task key prefix, must not be empty")
+		}
+		value = *prefix
+	}
+	if key == "periodic_priority_ladder" {
+		levels := splitConfigList(value)
+		seen := make(map[string]bool, len(levels))
+		for _, level := range levels {
+			if seen[level] {
+				return errors.New("invalid value for periodic priority ladder, levels must not repeat")
+			}
+			seen[level] = true
+		}
+		if len(levels) < 2 {
+			return errors.New("invalid value for periodic priority ladder, must list at least two comma-separated levels")
+		}
+	}
+	if key == "review_sla_default_approval_hours" || key == "review_sla_default_evaluation_hours" {
+		if hours, err := strconv.Atoi(value); err != nil || hours < 1 {
+			return errors.New("invalid value for review SLA default hours, must be a positive integer")
+		}
+	}
+	if key == "periodic_catch_up_window_hours" {
+		if hours, err := strconv.Atoi(value); err != nil || hours < 0 {
+			return errors.New("invalid value for periodic catch-up window hours, must be a non-negative integer")
+		}
+	}
+	if key == "status_light_mode" {
+		if value != statusLightModePercentage && value != statusLightModeHours {
+			return errors.New("invalid value for status light mode, must be 'percentage' or 'hours'")
+		}
+	}
+	if err := validateStatusLightConfig(key, value); err != nil {
+		return err
+	}
 	// 调用 config_repository.go 中的正确函数
-	return repository.UpdateSystemConfig(key, value)
+	if err := repository.UpdateSystemConfig(key, value); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("config key not found: " + key)
+		}
+		return err
+	}
+	if calibrationConfigKeys[key] {
+		scheduleRecalibration()
+	}
+	return nil
+}
+
+// statusLightConfigPairs 每个状态灯阈值配置项与其配对项：normal 上限不能超过同一模式下的 busy 上限
+var statusLightConfigPairs = map[string]struct {
+	other    string
+	isNormal bool
+}{
+	"status_light_normal_max_percentage": {"status_light_busy_max_percentage", true},
+	"status_light_busy_max_percentage":   {"status_light_normal_max_percentage", false},
+	"status_light_normal_max_hours":      {"status_light_busy_max_hours", true},
+	"status_light_busy_max_hours":        {"status_light_normal_max_hours", false},
+}
+
+// validateStatusLightConfig 校验状态灯阈值配置项，需要结合当前已保存的配对项一起判断
+func validateStatusLightConfig(key, value string) error {
+	pair, ok := statusLightConfigPairs[key]
+	if !ok {
+		return nil
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errors.New("invalid value for status light threshold, must be a number")
+	}
+	otherStr, err := repository.GetSystemConfigValueByKey(pair.other)
+	other, parseErr := strconv.ParseFloat(otherStr, 64)
+	if err != nil || parseErr != nil {
+		// 配对项缺失时只校验本项
+		return validateStatusLightRange(threshold, threshold)
+	}
+	if pair.isNormal {
+		return validateStatusLightRange(threshold, other)
+	}
+	return validateStatusLightRange(other, threshold)
+}
+
+// getConfigInt 读取一个整数类型的系统配置，读取或解析失败时返回默认值
+func getConfigInt(key string, defaultValue int) int {
+	valueStr, err := repository.GetSystemConfigValueByKey(key)
+	if err != nil {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getConfigFloat 读取一个浮点数类型的系统配置，读取或解析失败时返回默认值
+func getConfigFloat(key string, defaultValue float64) float64 {
+	valueStr, err := repository.GetSystemConfigValueByKey(key)
+	if err != nil {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getConfigBool 读取一个布尔类型的系统配置，读取或解析失败时返回默认值
+func getConfigBool(key string, defaultValue bool) bool {
+	valueStr, err := repository.GetSystemConfigValueByKey(key)
+	if err != nil {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getConfigList 读取一个逗号分隔的列表类型的系统配置，读取失败时返回默认值
+func getConfigList(key string, defaultValue []string) []string {
+	valueStr, err := repository.GetSystemConfigValueByKey(key)
+	if err != nil {
+		return defaultValue
+	}
+	return splitConfigList(valueStr)
+}
+
+// splitConfigList 将逗号分隔的配置值拆分为去除空白后的列表
+func splitConfigList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // --- 法定节假日相关 ---
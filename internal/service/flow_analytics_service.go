@@ -0,0 +1,269 @@
+// internal/service/flow_analytics_service.go
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultFlowAnalyticsDays = 84 // 默认最近 12 周
+	maxFlowAnalyticsDays     = 366
+)
+
+// flowStatuses 累积流图中的状态，按任务流转的先后顺序排列
+var flowStatuses = []string{"pending_review", "rejected", "in_pool", "in_progress", "pending_evaluation", "completed"}
+
+// FlowAnalyticsQuery 流动分析的查询参数
+// Period/Start/End 的格式与绩效报告相同，都不指定时为最近 12 周
+type FlowAnalyticsQuery struct {
+	Period     string
+	Start      string
+	End        string
+	TaskTypeID *uuid.UUID
+	Priority   string
+	Team       string
+}
+
+// DurationDistribution 一组时长 (小时) 的分布
+type DurationDistribution struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P75   float64 `json:"p75"`
+	P85   float64 `json:"p85"`
+	P95   float64 `json:"p95"`
+	Max   float64 `json:"max"`
+}
+
+// FlowDurations 各阶段时长分布，每项只统计结束时间点落在统计周期内的任务
+// LeadTime: 创建 -> 完成；ReviewWait: 创建 -> 审批通过；PoolWait: 进入任务池 -> 被领取或指派
+// CycleTime: 领取 -> 提交评价 (没有提交记录时到完成)；EvaluationWait: 提交评价 -> 完成评价
+type FlowDurations struct {
+	LeadTime       DurationDistribution `json:"lead_time"`
+	ReviewWait     DurationDistribution `json:"review_wait"`
+	PoolWait       DurationDistribution `json:"pool_wait"`
+	CycleTime      DurationDistribution `json:"cycle_time"`
+	EvaluationWait DurationDistribution `json:"evaluation_wait"`
+}
+
+// WeeklyThroughput 一周 (周一开始) 内完成的任务数和工时
+type WeeklyThroughput struct {
+	WeekStart      string `json:"week_start"`
+	CompletedTasks int    `json:"completed_tasks"`
+	Effort         int    `json:"effort"`
+}
+
+// CumulativeFlowDay 某一天结束时各状态的任务数
+type CumulativeFlowDay struct {
+	Date   string         `json:"date"`
+	Counts map[string]int `json:"counts"`
+}
+
+// FlowAnalytics 流动分析结果
+type FlowAnalytics struct {
+	Period         ReportPeriod        `json:"period"`
+	TaskCount      int                 `json:"task_count"`
+	Durations      FlowDurations       `json:"durations"`
+	Throughput     []WeeklyThroughput  `json:"throughput"`
+	Statuses       []string            `json:"statuses"`
+	CumulativeFlow []CumulativeFlowDay `json:"cumulative_flow"`
+}
+
+// GetFlowAnalyticsService 根据任务的时间戳计算各阶段时长分布、每周吞吐量和累积流图数据
+func GetFlowAnalyticsService(query FlowAnalyticsQuery) (FlowAnalytics, error) {
+	now := time.Now()
+	period, err := resolveFlowPeriod(query, now)
+	if err != nil {
+		return FlowAnalytics{}, err
+	}
+
+	tasks, err := repository.ListTasksForFlow(period.Start, period.End, repository.FlowTaskFilter{
+		TaskTypeID: query.TaskTypeID,
+		Priority:   query.Priority,
+		Team:       query.Team,
+	})
+	if err != nil {
+		return FlowAnalytics{}, err
+	}
+
+	return FlowAnalytics{
+		Period:         period,
+		TaskCount:      len(tasks),
+		Durations:      computeFlowDurations(tasks, period),
+		Throughput:     computeWeeklyThroughput(tasks, period),
+		Statuses:       flowStatuses,
+		CumulativeFlow: computeCumulativeFlow(tasks, period),
+	}, nil
+}
+
+// resolveFlowPeriod 解析统计周期，周期的结束时间不会晚于今天结束
+func resolveFlowPeriod(query FlowAnalyticsQuery, now time.Time) (ReportPeriod, error) {
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	if query.Period == "" && query.Start == "" && query.End == "" {
+		start := tomorrow.AddDate(0, 0, -defaultFlowAnalyticsDays)
+		return ReportPeriod{Label: "last 12 weeks", Start: start, End: tomorrow}, nil
+	}
+
+	period, err := resolveReportPeriod(ReportRequest{Period: query.Period, Start: query.Start, End: query.End}, now)
+	if err != nil {
+		return ReportPeriod{}, err
+	}
+	if period.End.After(tomorrow) {
+		period.End = tomorrow
+	}
+	if !period.Start.Before(period.End) {
+		return ReportPeriod{}, errors.New("period must not start in the future")
+	}
+	if period.End.Sub(period.Start) > maxFlowAnalyticsDays*24*time.Hour {
+		return ReportPeriod{}, errors.New("period cannot exceed 366 days")
+	}
+	return period, nil
+}
+
+// poolEnteredAt 任务进入任务池的时间：经过审批的任务为审批通过时间，
+// 子任务和计划任务创建后直接进入任务池，为创建时间；尚未进入任务池时返回 nil
+func poolEnteredAt(task model.Task) *time.Time {
+	if task.ApprovedAt != nil {
+		return task.ApprovedAt
+	}
+	if task.ClaimedAt != nil || (task.Status != "pending_review" && task.Status != "rejected") {
+		return &task.CreatedAt
+	}
+	return nil
+}
+
+// inPeriod 判断时间点是否落在统计周期内
+func inPeriod(t *time.Time, period ReportPeriod) bool {
+	return t != nil && !t.Before(period.Start) && t.Before(period.End)
+}
+
+func computeFlowDurations(tasks []model.Task, period ReportPeriod) FlowDurations {
+	var lead, review, pool, cycle, evaluation []float64
+	hoursBetween := func(from, to time.Time) float64 {
+		return math.Max(to.Sub(from).Hours(), 0)
+	}
+
+	for _, task := range tasks {
+		if inPeriod(task.CompletedAt, period) {
+			lead = append(lead, hoursBetween(task.CreatedAt, *task.CompletedAt))
+			if task.SubmittedAt != nil {
+				evaluation = append(evaluation, hoursBetween(*task.SubmittedAt, *task.CompletedAt))
+			}
+		}
+		if inPeriod(task.ApprovedAt, period) {
+			review = append(review, hoursBetween(task.CreatedAt, *task.ApprovedAt))
+		}
+		if inPeriod(task.ClaimedAt, period) {
+			if entered := poolEnteredAt(task); entered != nil {
+				pool = append(pool, hoursBetween(*entered, *task.ClaimedAt))
+			}
+		}
+		if task.ClaimedAt != nil {
+			workEnd := task.SubmittedAt
+			if workEnd == nil {
+				workEnd = task.CompletedAt
+			}
+			if inPeriod(workEnd, period) {
+				cycle = append(cycle, hoursBetween(*task.ClaimedAt, *workEnd))
+			}
+		}
+	}
+
+	return FlowDurations{
+		LeadTime:       buildDurationDistribution(lead),
+		ReviewWait:     buildDurationDistribution(review),
+		PoolWait:       buildDurationDistribution(pool),
+		CycleTime:      buildDurationDistribution(cycle),
+		EvaluationWait: buildDurationDistribution(evaluation),
+	}
+}
+
+func buildDurationDistribution(hours []float64) DurationDistribution {
+	distribution := DurationDistribution{Count: len(hours)}
+	if len(hours) == 0 {
+		return distribution
+	}
+	distribution.Mean = roundScore(average(hours))
+	distribution.P50 = roundScore(percentile(hours, 50))
+	distribution.P75 = roundScore(percentile(hours, 75))
+	distribution.P85 = roundScore(percentile(hours, 85))
+	distribution.P95 = roundScore(percentile(hours, 95))
+	distribution.Max = roundScore(percentile(hours, 100))
+	return distribution
+}
+
+// weekStart 返回某一天所在周的周一零点
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func computeWeeklyThroughput(tasks []model.Task, period ReportPeriod) []WeeklyThroughput {
+	weeks := []WeeklyThroughput{}
+	index := make(map[string]int)
+	for week := weekStart(period.Start); week.Before(period.End); week = week.AddDate(0, 0, 7) {
+		key := week.Format("2006-01-02")
+		index[key] = len(weeks)
+		weeks = append(weeks, WeeklyThroughput{WeekStart: key})
+	}
+
+	for _, task := range tasks {
+		if !inPeriod(task.CompletedAt, period) {
+			continue
+		}
+		i := index[weekStart(task.CompletedAt.In(period.Start.Location())).Format("2006-01-02")]
+		weeks[i].CompletedTasks++
+		weeks[i].Effort += task.Effort
+	}
+	return weeks
+}
+
+// flowStatusAt 根据时间戳推算任务在某一时刻的状态，任务尚未创建时返回空字符串
+// 时间戳只记录了每个阶段第一次进入的时间，被驳回的任务只能从最后一次更新时间起计为 rejected
+func flowStatusAt(task model.Task, at time.Time) string {
+	reached := func(t *time.Time) bool { return t != nil && !t.After(at) }
+	switch {
+	case task.CreatedAt.After(at):
+		return ""
+	case reached(task.CompletedAt):
+		return "completed"
+	case reached(task.SubmittedAt):
+		return "pending_evaluation"
+	case reached(task.ClaimedAt):
+		return "in_progress"
+	case reached(poolEnteredAt(task)):
+		return "in_pool"
+	case task.Status == "rejected" && !task.UpdatedAt.After(at):
+		return "rejected"
+	default:
+		return "pending_review"
+	}
+}
+
+// computeCumulativeFlow 计算统计周期内每天结束时各状态的任务数
+// 在统计周期开始前已完成的任务不在查询结果中，completed 从周期开始时累积
+func computeCumulativeFlow(tasks []model.Task, period ReportPeriod) []CumulativeFlowDay {
+	days := []CumulativeFlowDay{}
+	for day := period.Start; day.Before(period.End); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		counts := make(map[string]int, len(flowStatuses))
+		for _, status := range flowStatuses {
+			counts[status] = 0
+		}
+		for _, task := range tasks {
+			if status := flowStatusAt(task, endOfDay); status != "" {
+				counts[status]++
+			}
+		}
+		days = append(days, CumulativeFlowDay{Date: day.Format("2006-01-02"), Counts: counts})
+	}
+	return days
+}
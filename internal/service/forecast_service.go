@@ -0,0 +1,195 @@
+// internal/service/forecast_service.go
+package service
+
+import (
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultForecastWeeks = 4
+	maxForecastWeeks     = 26
+)
+
+// ForecastDay 某个成员在某一天的预测负载
+type ForecastDay struct {
+	Date           string  `json:"date"`
+	LoadHours      float64 `json:"load_hours"`
+	CapacityHours  float64 `json:"capacity_hours"`
+	LoadPercentage float64 `json:"load_percentage"`
+	StatusLight    string  `json:"status_light"`
+	DayType        string  `json:"day_type"` // 'working', 'weekend', 'holiday', 'leave'
+}
+
+// MemberForecast 单个成员在预测期内的逐日负载 (时间序列)
+type MemberForecast struct {
+	User               model.User    `json:"user"`
+	Days               []ForecastDay `json:"days"`
+	TotalLoadHours     float64       `json:"total_load_hours"`
+	TotalCapacityHours float64       `json:"total_capacity_hours"`
+	PeakLoadPercentage float64       `json:"peak_load_percentage"`
+}
+
+// ForecastMatrix 适合热力图展示的矩阵：Values[i][j] 为 UserIDs[i] 在 Dates[j] 的负载百分比
+type ForecastMatrix struct {
+	UserIDs   []string    `json:"user_ids"`
+	UserNames []string    `json:"user_names"`
+	Dates     []string    `json:"dates"`
+	Values    [][]float64 `json:"values"`
+}
+
+// CapacityForecast 产能预测的完整结果
+type CapacityForecast struct {
+	StartDate string           `json:"start_date"`
+	EndDate   string           `json:"end_date"`
+	Series    []MemberForecast `json:"series"`
+	Matrix    ForecastMatrix   `json:"matrix"`
+}
+
+// GetCapacityForecastService 预测未来 weeks 周内每个成员的逐日负载
+// 与人员看板相同，无截止日期或已超期的任务全部压在今天，其余任务按可用工作日线性分摊到截止日期 (只分摊到可用的日期上)
+func GetCapacityForecastService(weeks int) (CapacityForecast, error) {
+	if weeks <= 0 {
+		weeks = defaultForecastWeeks
+	}
+	if weeks > maxForecastWeeks {
+		weeks = maxForecastWeeks
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endDate := today.AddDate(0, 0, weeks*7-1)
+
+	members, err := repository.FindAllActiveMembers()
+	if err != nil {
+		return CapacityForecast{}, err
+	}
+	memberIDs := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+	}
+
+	// 一次性获取所有成员正在进行的任务，并按负责人分组
+	allTasks, err := repository.FindInProgressTasksForUsers(memberIDs)
+	if err != nil {
+		return CapacityForecast{}, err
+	}
+	tasksByUser := make(map[uuid.UUID][]model.Task)
+	for _, task := range allTasks {
+		if task.AssigneeID != nil {
+			tasksByUser[*task.AssigneeID] = append(tasksByUser[*task.AssigneeID], task)
+		}
+	}
+
+	// 日历数据 (节假日、请假) 一次性查询，覆盖预测期以及预测期之后才截止的任务
+	calendar, err := loadWorkCalendar(memberIDs, today, latestDueDate(allTasks, endDate))
+	if err != nil {
+		return CapacityForecast{}, err
+	}
+
+	var dates []string
+	for d := today; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+
+	globalDailyHours := getGlobalDailyHours()
+	lightSettings := loadStatusLightSettings()
+	result := CapacityForecast{
+		StartDate: today.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Matrix:    ForecastMatrix{Dates: dates},
+	}
+
+	for _, member := range members {
+		isAvailable := calendar.availableFor(member.ID)
+		leaves := calendar.leaves[member.ID]
+		dayType := func(day time.Time) string {
+			key := day.Format("2006-01-02")
+			switch {
+			case day.Weekday() == time.Saturday || day.Weekday() == time.Sunday:
+				return "weekend"
+			case calendar.holidays[key]:
+				return "holiday"
+			case leaves[key]:
+				return "leave"
+			default:
+				return "working"
+			}
+		}
+
+		loadByDate := distributeTaskLoad(tasksByUser[member.ID], now, isAvailable)
+		dailyCapacity := resolveDailyCapacity(member, globalDailyHours)
+		thresholds := lightSettings.forUser(member)
+
+		forecast := MemberForecast{User: member}
+		row := make([]float64, 0, len(dates))
+		for d := today; !d.After(endDate); d = d.AddDate(0, 0, 1) {
+			key := d.Format("2006-01-02")
+			day := ForecastDay{Date: key, LoadHours: loadByDate[key], DayType: dayType(d)}
+			if day.DayType == "working" {
+				day.CapacityHours = dailyCapacity
+			}
+			if day.CapacityHours > 0 {
+				day.LoadPercentage = (day.LoadHours / day.CapacityHours) * 100
+			} else if day.LoadHours > 0 {
+				// 非工作日仍有负载 (例如今天是周末，但有压在今天的超期任务)，视为超载
+				day.LoadPercentage = 100
+			}
+			day.StatusLight = calculateStatusLight(thresholds, day.LoadHours, day.LoadPercentage)
+
+			forecast.Days = append(forecast.Days, day)
+			forecast.TotalLoadHours += day.LoadHours
+			forecast.TotalCapacityHours += day.CapacityHours
+			if day.LoadPercentage > forecast.PeakLoadPercentage {
+				forecast.PeakLoadPercentage = day.LoadPercentage
+			}
+			row = append(row, day.LoadPercentage)
+		}
+
+		result.Series = append(result.Series, forecast)
+		result.Matrix.UserIDs = append(result.Matrix.UserIDs, member.ID.String())
+		result.Matrix.UserNames = append(result.Matrix.UserNames, member.RealName)
+		result.Matrix.Values = append(result.Matrix.Values, row)
+	}
+
+	return result, nil
+}
+
+// distributeTaskLoad 将进行中任务的工时分摊到具体日期上 (日期字符串 -> 工时)，用于产能预测和指派推荐的逐日负载
+// 超期的判定与人员看板 (sumDailyLoad) 一致；与人员看板不同，不可用的日期不分摊负载
+// 无截止日期的任务全部工时都算作“技术债务”，已超期的任务全部剩余工时，都压在今天；
+// 其余任务在今天到截止日期之间的可用工作日上平均分摊，没有可用工作日 (比如截止日期是今天，但今天是节假日或请假日) 时全部压在今天
+func distributeTaskLoad(tasks []model.Task, now time.Time, isAvailable func(time.Time) bool) map[string]float64 {
+	loadByDate := make(map[string]float64)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	todayKey := today.Format("2006-01-02")
+
+	for _, task := range tasks {
+		effort := float64(task.Effort)
+		if task.DueDate == nil || isTaskOverdue(task, now) {
+			loadByDate[todayKey] += effort
+			continue
+		}
+
+		due := time.Date(task.DueDate.Year(), task.DueDate.Month(), task.DueDate.Day(), 0, 0, 0, 0, now.Location())
+		var availableDays []string
+		for d := today; !d.After(due); d = d.AddDate(0, 0, 1) {
+			if isAvailable(d) {
+				availableDays = append(availableDays, d.Format("2006-01-02"))
+			}
+		}
+		if len(availableDays) == 0 {
+			loadByDate[todayKey] += effort
+			continue
+		}
+		dailyEffort := effort / float64(len(availableDays))
+		for _, key := range availableDays {
+			loadByDate[key] += dailyEffort
+		}
+	}
+	return loadByDate
+}
@@ -0,0 +1,260 @@
+// internal/service/job_service.go
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobHandler 处理一种类型的后台作业，返回错误时作业会按指数退避重试
+type JobHandler func(payload json.RawMessage) error
+
+var (
+	jobHandlers   = make(map[string]JobHandler)
+	jobHandlersMu sync.RWMutex
+	workersOnce   sync.Once
+)
+
+const (
+	defaultJobMaxAttempts = 5
+	jobBaseBackoff        = 30 * time.Second // 第一次重试前的等待时间，之后每次翻倍
+	jobMaxBackoff         = time.Hour
+	jobStaleTimeout       = 10 * time.Minute // 执行中超过该时长的作业视为其实例已崩溃，重新排队
+	jobIdlePollInterval   = 2 * time.Second
+)
+
+// RegisterJobHandler 注册一种作业类型的处理函数 (通常在 init 中调用)
+func RegisterJobHandler(jobType string, handler JobHandler) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	jobHandlers[jobType] = handler
+}
+
+// EnqueueJob 将一个作业加入队列，payload 会被序列化为 JSON
+func EnqueueJob(jobType string, payload interface{}) error {
+	return EnqueueJobTx(nil, jobType, payload)
+}
+
+// EnqueueJobTx 在调用方的事务中将作业加入队列，事务回滚时作业也不会被执行
+func EnqueueJobTx(tx *gorm.DB, jobType string, payload interface{}) error {
+	job, err := newJob(jobType, payload)
+	if err != nil {
+		return err
+	}
+	return repository.CreateJob(tx, &job)
+}
+
+// EnqueueCoalescedJob 将作业加入队列，但队列中已有同类型的待执行作业时不再重复入队
+// 适用于只关心“最终再执行一次”的作业 (如重新校准)，短时间内的多次触发合并为一次执行
+func EnqueueCoalescedJob(jobType string, payload interface{}) error {
+	job, err := newJob(jobType, payload)
+	if err != nil {
+		return err
+	}
+	_, err = repository.CreateJobUnlessPending(&job)
+	return err
+}
+
+// newJob 校验作业类型并构造一个立即可执行的待执行作业
+func newJob(jobType string, payload interface{}) (model.Job, error) {
+	jobHandlersMu.RLock()
+	_, known := jobHandlers[jobType]
+	jobHandlersMu.RUnlock()
+	if !known {
+		return model.Job{}, fmt.Errorf("unknown job type '%s'", jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return model.Job{}, err
+	}
+	return model.Job{
+		JobType:     jobType,
+		Payload:     data,
+		Status:      "pending",
+		MaxAttempts: defaultJobMaxAttempts,
+		RunAt:       time.Now(),
+	}, nil
+}
+
+// StartJobWorkers 启动指定数量的 worker 从队列中领取并执行作业 (多次调用只会启动一次)
+// 每个实例都可以启动 worker，作业通过 SKIP LOCKED 领取，不会被重复执行
+func StartJobWorkers(count int) {
+	if count <= 0 {
+		log.Println("Job workers are disabled.")
+		return
+	}
+	workersOnce.Do(func() {
+		hostname, _ := os.Hostname()
+		prefix := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+		for i := 0; i < count; i++ {
+			go runJobWorker(fmt.Sprintf("%s-worker-%d", prefix, i+1))
+		}
+		go requeueStaleJobsLoop()
+		log.Printf("Started %d job workers.", count)
+	})
+}
+
+// runJobWorker 循环领取并执行作业，队列为空时短暂休眠
+func runJobWorker(workerID string) {
+	for {
+		job, found, err := repository.ClaimNextJob(workerID)
+		if err != nil {
+			log.Printf("Job worker %s failed to claim job: %v", workerID, err)
+			time.Sleep(jobIdlePollInterval)
+			continue
+		}
+		if !found {
+			time.Sleep(jobIdlePollInterval)
+			continue
+		}
+		executeJob(job)
+	}
+}
+
+// executeJob 执行一个已领取的作业，并根据结果标记成功、重试或转入死信
+func executeJob(job model.Job) {
+	jobHandlersMu.RLock()
+	handler, ok := jobHandlers[job.JobType]
+	jobHandlersMu.RUnlock()
+
+	var err error
+	if !ok {
+		err = fmt.Errorf("no handler registered for job type '%s'", job.JobType)
+	} else {
+		err = runJobHandler(handler, job)
+	}
+
+	// 作业执行超时被重新排队后可能已由其他 worker 领取，此时不再覆盖其状态
+	if err == nil {
+		marked, markErr := repository.MarkJobSucceeded(job.ID, job.LockedBy)
+		if markErr != nil {
+			log.Printf("Failed to mark job %d as succeeded: %v", job.ID, markErr)
+		} else if !marked {
+			log.Printf("Job %d (%s) finished after its lock was lost, result not recorded.", job.ID, job.JobType)
+		}
+		return
+	}
+
+	dead := job.Attempts >= job.MaxAttempts
+	nextRunAt := time.Now().Add(jobBackoff(job.Attempts))
+	if dead {
+		log.Printf("Job %d (%s) failed permanently after %d attempts: %v", job.ID, job.JobType, job.Attempts, err)
+	} else {
+		log.Printf("Job %d (%s) failed (attempt %d/%d), retrying at %s: %v", job.ID, job.JobType, job.Attempts, job.MaxAttempts, nextRunAt.Format(time.RFC3339), err)
+	}
+	marked, markErr := repository.MarkJobFailed(job.ID, job.LockedBy, err.Error(), nextRunAt, dead)
+	if markErr != nil {
+		log.Printf("Failed to record failure of job %d: %v", job.ID, markErr)
+	} else if !marked {
+		log.Printf("Job %d (%s) failed after its lock was lost, result not recorded.", job.ID, job.JobType)
+	}
+}
+
+// runJobHandler 执行处理函数，处理函数 panic 时视为一次失败，避免 worker 退出
+func runJobHandler(handler JobHandler, job model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+	return handler(json.RawMessage(job.Payload))
+}
+
+// jobBackoff 计算第 attempts 次失败后的重试等待时间 (指数退避，有上限)
+func jobBackoff(attempts int) time.Duration {
+	backoff := jobBaseBackoff
+	for i := 1; i < attempts && backoff < jobMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > jobMaxBackoff {
+		backoff = jobMaxBackoff
+	}
+	return backoff
+}
+
+// requeueStaleJobsLoop 定期将卡在执行中的作业重新排队 (尝试次数用完的转入死信)，保证实例崩溃后作业仍能完成
+func requeueStaleJobsLoop() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		requeued, dead, err := repository.RequeueStaleJobs(time.Now().Add(-jobStaleTimeout))
+		if err != nil {
+			log.Printf("Failed to requeue stale jobs: %v", err)
+			continue
+		}
+		if requeued > 0 {
+			log.Printf("Requeued %d stale jobs.", requeued)
+		}
+		if dead > 0 {
+			log.Printf("Moved %d stale jobs to dead letter after exhausting their attempts.", dead)
+		}
+	}
+}
+
+// --- 管理接口 ---
+
+// ListJobsService 查询作业队列，可按状态和类型过滤
+func ListJobsService(status, jobType string, limit int) ([]model.Job, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return repository.ListJobs(status, jobType, limit)
+}
+
+// GetJobService 查询单个作业
+func GetJobService(id uint64) (model.Job, error) {
+	job, err := repository.FindJobByID(id)
+	if err != nil {
+		return model.Job{}, errors.New("job not found")
+	}
+	return job, nil
+}
+
+// RetryJobService 手动重试一个已转入死信的作业
+func RetryJobService(id uint64) (model.Job, error) {
+	job, err := repository.FindJobByID(id)
+	if err != nil {
+		return model.Job{}, errors.New("job not found")
+	}
+	if job.Status != "dead" {
+		return model.Job{}, errors.New("only dead jobs can be retried")
+	}
+	if err := repository.RetryJob(id); err != nil {
+		return model.Job{}, err
+	}
+	return repository.FindJobByID(id)
+}
+
+// --- 作业类型 ---
+
+// JobTypeCascadeSubtaskAssignee 转交被接受后，将原负责人名下的子任务一并转给新负责人
+const JobTypeCascadeSubtaskAssignee = "cascade_subtask_assignee"
+
+// CascadeSubtaskAssigneePayload 子任务级联转交作业的参数
+type CascadeSubtaskAssigneePayload struct {
+	ParentTaskID uint      `json:"parent_task_id"`
+	FromUserID   uuid.UUID `json:"from_user_id"`
+	ToUserID     uuid.UUID `json:"to_user_id"`
+}
+
+func init() {
+	RegisterJobHandler(JobTypeCascadeSubtaskAssignee, func(payload json.RawMessage) error {
+		var p CascadeSubtaskAssigneePayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return repository.BatchUpdateSubtasksAssignee(p.ParentTaskID, p.FromUserID, p.ToUserID)
+	})
+}
@@ -0,0 +1,388 @@
+// internal/service/performance_service.go
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+const (
+	defaultPerformanceRollingDays = 90
+	maxPerformanceRollingDays     = 730
+)
+
+// evaluationDimensions 评价的四个维度
+var evaluationDimensions = []string{"timeliness", "quality", "collaboration", "complexity"}
+
+// PerformanceQuery 绩效查询参数
+// Window: 'month' 本自然月, 'quarter' 本季度, 'rolling' 最近 Days 天
+// Weighting: 'none' 每个任务同等权重, 'effort' 按工时加权, 'difficulty' 按技术难度综合分加权, 'effort_difficulty' 两者相乘
+// ScoreBasis: 'raw' 原始评价分, 'normalized' 按评价人打分习惯校准后的评价分，为空时使用系统配置 performance_score_basis
+type PerformanceQuery struct {
+	Window     string
+	Days       int
+	Weighting  string
+	MinSamples int // 小于等于 0 时使用系统配置 performance_min_sample_size
+	ScoreBasis string
+}
+
+// PerformanceWindow 一个统计时间窗口 [Start, End)
+type PerformanceWindow struct {
+	Label string    `json:"label"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// PerformanceScores 一个时间窗口内的加权评价得分，样本数不足最低要求时 Sufficient 为 false 且不给出得分
+// 使用校准分时，RawFallbackCount 为尚未校准、以原始分计入的样本数
+type PerformanceScores struct {
+	SampleSize       int      `json:"sample_size"`
+	RawFallbackCount int      `json:"raw_fallback_count"`
+	TotalEffort      int      `json:"total_effort"`
+	Sufficient       bool     `json:"sufficient"`
+	CompositeScore   *float64 `json:"composite_score,omitempty"`
+	AvgTimeliness    *float64 `json:"avg_timeliness,omitempty"`
+	AvgQuality       *float64 `json:"avg_quality,omitempty"`
+	AvgCollaboration *float64 `json:"avg_collaboration,omitempty"`
+	AvgComplexity    *float64 `json:"avg_complexity,omitempty"`
+}
+
+// PerformanceTrend 本窗口相对上一个窗口的得分变化 (两个窗口样本都充足时才计算)
+type PerformanceTrend struct {
+	CompositeScore   float64 `json:"composite_score"`
+	AvgTimeliness    float64 `json:"avg_timeliness"`
+	AvgQuality       float64 `json:"avg_quality"`
+	AvgCollaboration float64 `json:"avg_collaboration"`
+	AvgComplexity    float64 `json:"avg_complexity"`
+}
+
+// PerformanceReport 当前窗口、上一个窗口的得分及变化趋势
+type PerformanceReport struct {
+	Window         PerformanceWindow `json:"window"`
+	PreviousWindow PerformanceWindow `json:"previous_window"`
+	Weighting      string            `json:"weighting"`
+	ScoreBasis     string            `json:"score_basis"`
+	MinSamples     int               `json:"min_samples"`
+	Current        PerformanceScores `json:"current"`
+	Previous       PerformanceScores `json:"previous"`
+	Trend          *PerformanceTrend `json:"trend,omitempty"`
+}
+
+// UserPerformance 单个用户的绩效报告
+type UserPerformance struct {
+	User model.User `json:"user"`
+	PerformanceReport
+}
+
+// TeamPerformance 单个团队的绩效报告 (团队所有成员的任务合并计算) 及成员明细
+type TeamPerformance struct {
+	Team        string `json:"team"`
+	MemberCount int    `json:"member_count"`
+	PerformanceReport
+	Members []UserPerformance `json:"members"`
+}
+
+// GetUserPerformanceService 计算单个用户在指定时间窗口内的绩效
+func GetUserPerformanceService(userID uuid.UUID, query PerformanceQuery) (UserPerformance, error) {
+	user, err := repository.FindUserByID(userID)
+	if err != nil {
+		return UserPerformance{}, errors.New("user not found")
+	}
+	if err := normalizePerformanceQuery(&query); err != nil {
+		return UserPerformance{}, err
+	}
+	current, previous := resolvePerformanceWindows(query, time.Now())
+	tasks, err := repository.ListEvaluatedTasksInRange([]uuid.UUID{userID}, previous.Start, current.End)
+	if err != nil {
+		return UserPerformance{}, err
+	}
+	return UserPerformance{User: user, PerformanceReport: buildPerformanceReport(tasks, query, current, previous)}, nil
+}
+
+// GetTeamPerformanceService 按 User.Team 计算各团队的绩效，team 不为空时只返回该团队
+func GetTeamPerformanceService(team string, query PerformanceQuery) ([]TeamPerformance, error) {
+	if err := normalizePerformanceQuery(&query); err != nil {
+		return nil, err
+	}
+	members, err := repository.FindAllActiveMembers()
+	if err != nil {
+		return nil, err
+	}
+	team = strings.TrimSpace(team)
+
+	type teamGroup struct {
+		name    string
+		members []model.User
+	}
+	groups := make(map[string]*teamGroup)
+	var memberIDs []uuid.UUID
+	for _, member := range members {
+		name := strings.TrimSpace(member.Team)
+		if team != "" && !strings.EqualFold(name, team) {
+			continue
+		}
+		key := strings.ToLower(name)
+		if groups[key] == nil {
+			groups[key] = &teamGroup{name: name}
+		}
+		groups[key].members = append(groups[key].members, member)
+		memberIDs = append(memberIDs, member.ID)
+	}
+
+	current, previous := resolvePerformanceWindows(query, time.Now())
+	tasks, err := repository.ListEvaluatedTasksInRange(memberIDs, previous.Start, current.End)
+	if err != nil {
+		return nil, err
+	}
+	tasksByUser := make(map[uuid.UUID][]model.Task)
+	for _, task := range tasks {
+		if task.AssigneeID != nil {
+			tasksByUser[*task.AssigneeID] = append(tasksByUser[*task.AssigneeID], task)
+		}
+	}
+
+	result := make([]TeamPerformance, 0, len(groups))
+	for _, group := range groups {
+		var teamTasks []model.Task
+		tp := TeamPerformance{Team: group.name, MemberCount: len(group.members), Members: []UserPerformance{}}
+		for _, member := range group.members {
+			memberTasks := tasksByUser[member.ID]
+			teamTasks = append(teamTasks, memberTasks...)
+			tp.Members = append(tp.Members, UserPerformance{
+				User:              member,
+				PerformanceReport: buildPerformanceReport(memberTasks, query, current, previous),
+			})
+		}
+		tp.PerformanceReport = buildPerformanceReport(teamTasks, query, current, previous)
+		sort.SliceStable(tp.Members, func(i, j int) bool {
+			return tp.Members[i].User.RealName < tp.Members[j].User.RealName
+		})
+		result = append(result, tp)
+	}
+
+	// 未设置团队的分组放在最后
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Team == "") != (result[j].Team == "") {
+			return result[j].Team == ""
+		}
+		return result[i].Team < result[j].Team
+	})
+	return result, nil
+}
+
+// --- 辅助函数 ---
+
+// normalizePerformanceQuery 校验查询参数并填充默认值
+func normalizePerformanceQuery(query *PerformanceQuery) error {
+	switch query.Window {
+	case "":
+		query.Window = "quarter"
+	case "month", "quarter":
+	case "rolling":
+		if query.Days <= 0 {
+			query.Days = defaultPerformanceRollingDays
+		}
+		if query.Days > maxPerformanceRollingDays {
+			return errors.New("rolling window cannot exceed 730 days")
+		}
+	default:
+		return errors.New("window must be 'month', 'quarter' or 'rolling'")
+	}
+	switch query.Weighting {
+	case "":
+		query.Weighting = "effort"
+	case "none", "effort", "difficulty", "effort_difficulty":
+	default:
+		return errors.New("weighting must be 'none', 'effort', 'difficulty' or 'effort_difficulty'")
+	}
+	switch query.ScoreBasis {
+	case "":
+		query.ScoreBasis = defaultScoreBasis()
+	case "raw", "normalized":
+	default:
+		return errors.New("score_basis must be 'raw' or 'normalized'")
+	}
+	if query.MinSamples <= 0 {
+		query.MinSamples = getConfigInt("performance_min_sample_size", 3)
+	}
+	return nil
+}
+
+// resolvePerformanceWindows 计算当前窗口和紧邻的上一个窗口
+// 自然月/季度的当前窗口截止到 now，上一个窗口为完整的上个月/上个季度
+func resolvePerformanceWindows(query PerformanceQuery, now time.Time) (PerformanceWindow, PerformanceWindow) {
+	switch query.Window {
+	case "month":
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		prevStart := start.AddDate(0, -1, 0)
+		return PerformanceWindow{Label: start.Format("2006-01"), Start: start, End: now},
+			PerformanceWindow{Label: prevStart.Format("2006-01"), Start: prevStart, End: start}
+	case "rolling":
+		start := now.AddDate(0, 0, -query.Days)
+		prevStart := start.AddDate(0, 0, -query.Days)
+		return PerformanceWindow{Label: "last " + strconv.Itoa(query.Days) + " days", Start: start, End: now},
+			PerformanceWindow{Label: "previous " + strconv.Itoa(query.Days) + " days", Start: prevStart, End: start}
+	default:
+		startMonth := time.Month((int(now.Month())-1)/3*3 + 1)
+		start := time.Date(now.Year(), startMonth, 1, 0, 0, 0, 0, now.Location())
+		prevStart := start.AddDate(0, -3, 0)
+		return PerformanceWindow{Label: quarterLabel(start), Start: start, End: now},
+			PerformanceWindow{Label: quarterLabel(prevStart), Start: prevStart, End: start}
+	}
+}
+
+// buildPerformanceReport 将任务按完成时间分到两个窗口并分别计算得分和趋势
+func buildPerformanceReport(tasks []model.Task, query PerformanceQuery, current, previous PerformanceWindow) PerformanceReport {
+	var currentTasks, previousTasks []model.Task
+	for _, task := range tasks {
+		if task.CompletedAt == nil {
+			continue
+		}
+		if !task.CompletedAt.Before(current.Start) {
+			currentTasks = append(currentTasks, task)
+		} else if !task.CompletedAt.Before(previous.Start) {
+			previousTasks = append(previousTasks, task)
+		}
+	}
+
+	report := PerformanceReport{
+		Window:         current,
+		PreviousWindow: previous,
+		Weighting:      query.Weighting,
+		ScoreBasis:     query.ScoreBasis,
+		MinSamples:     query.MinSamples,
+		Current:        scorePerformance(currentTasks, query),
+		Previous:       scorePerformance(previousTasks, query),
+	}
+	if report.Current.Sufficient && report.Previous.Sufficient {
+		report.Trend = &PerformanceTrend{
+			CompositeScore:   roundScore(*report.Current.CompositeScore - *report.Previous.CompositeScore),
+			AvgTimeliness:    roundScore(*report.Current.AvgTimeliness - *report.Previous.AvgTimeliness),
+			AvgQuality:       roundScore(*report.Current.AvgQuality - *report.Previous.AvgQuality),
+			AvgCollaboration: roundScore(*report.Current.AvgCollaboration - *report.Previous.AvgCollaboration),
+			AvgComplexity:    roundScore(*report.Current.AvgComplexity - *report.Previous.AvgComplexity),
+		}
+	}
+	return report
+}
+
+// scorePerformance 计算一组任务的加权平均得分
+func scorePerformance(tasks []model.Task, query PerformanceQuery) PerformanceScores {
+	scores := PerformanceScores{}
+	sums := make(map[string]float64)
+	var compositeSum, weightSum float64
+
+	for _, task := range tasks {
+		evaluation, rawFallback, ok := taskScoresForBasis(task, query.ScoreBasis)
+		if !ok {
+			continue
+		}
+		if rawFallback {
+			scores.RawFallbackCount++
+		}
+		weight := performanceWeight(task, query.Weighting)
+		for _, dimension := range evaluationDimensions {
+			sums[dimension] += evaluation[dimension] * weight
+		}
+		compositeSum += evaluation["composite_score"] * weight
+		weightSum += weight
+		scores.SampleSize++
+		scores.TotalEffort += task.Effort
+	}
+
+	scores.Sufficient = scores.SampleSize >= query.MinSamples && weightSum > 0
+	if !scores.Sufficient {
+		return scores
+	}
+	avg := func(sum float64) *float64 {
+		value := roundScore(sum / weightSum)
+		return &value
+	}
+	scores.CompositeScore = avg(compositeSum)
+	scores.AvgTimeliness = avg(sums["timeliness"])
+	scores.AvgQuality = avg(sums["quality"])
+	scores.AvgCollaboration = avg(sums["collaboration"])
+	scores.AvgComplexity = avg(sums["complexity"])
+	return scores
+}
+
+// taskScoresForBasis 按 basis 取任务的评价分，'normalized' 时优先使用校准分，尚未校准的任务回退到原始分 (rawFallback 为 true)
+func taskScoresForBasis(task model.Task, basis string) (scores map[string]float64, rawFallback bool, ok bool) {
+	if basis == "normalized" {
+		if scores, ok := parseEvaluationScores(task.NormalizedEvaluation); ok {
+			return scores, false, true
+		}
+	}
+	scores, ok = taskEvaluationScores(task)
+	return scores, ok && basis == "normalized", ok
+}
+
+// taskEvaluationScores 解析任务的原始评价
+func taskEvaluationScores(task model.Task) (map[string]float64, bool) {
+	return parseEvaluationScores(task.Evaluation)
+}
+
+// parseEvaluationScores 解析评价 JSON，缺少 composite_score 时用四个维度的平均值补齐
+func parseEvaluationScores(data datatypes.JSON) (map[string]float64, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false
+	}
+	scores := make(map[string]float64)
+	var sum float64
+	for _, dimension := range evaluationDimensions {
+		value, ok := raw[dimension].(float64)
+		if !ok {
+			return nil, false
+		}
+		scores[dimension] = value
+		sum += value
+	}
+	if composite, ok := raw["composite_score"].(float64); ok {
+		scores["composite_score"] = composite
+	} else {
+		scores["composite_score"] = sum / float64(len(evaluationDimensions))
+	}
+	return scores, true
+}
+
+// performanceWeight 计算单个任务的权重，工时和难度缺失时按 1 处理，避免任务被完全忽略
+func performanceWeight(task model.Task, weighting string) float64 {
+	effort := math.Max(float64(task.Effort), 1)
+	difficulty := 1.0
+	if score, ok := taskCompositeDifficulty(task); ok {
+		difficulty = score
+	}
+	switch weighting {
+	case "effort":
+		return effort
+	case "difficulty":
+		return difficulty
+	case "effort_difficulty":
+		return effort * difficulty
+	default:
+		return 1
+	}
+}
+
+func roundScore(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+func quarterLabel(start time.Time) string {
+	return strconv.Itoa(start.Year()) + "-Q" + strconv.Itoa((int(start.Month())-1)/3+1)
+}
@@ -0,0 +1,420 @@
+// internal/service/periodic_policy_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+	"gotasksys/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// holidayShiftLookaheadDays 提前到上一个工作日时，向后查看多少天内的触发 (需覆盖最长的法定长假)
+const holidayShiftLookaheadDays = 30
+
+var validHolidayPolicies = map[string]bool{"none": true, "skip": true, "next_working_day": true, "previous_working_day": true}
+var validLeaveFallbacks = map[string]bool{"none": true, "pool": true, "backup": true}
+var validDueOffsetTypes = map[string]bool{"none": true, "working_days": true, "end_of_week": true, "end_of_month": true}
+var validOverlapPolicies = map[string]bool{"always": true, "skip_if_open": true, "roll_forward": true}
+
+// openPeriodicTaskStatuses 重叠策略中视为"仍未完成"的任务状态
+var openPeriodicTaskStatuses = []string{"in_pool", "in_progress"}
+
+// defaultPriorityLadder 顺延旧任务时逐级提升的优先级，系统配置 'periodic_priority_ladder' 未设置时使用
+var defaultPriorityLadder = []string{"low", "medium", "high", "urgent"}
+
+// maxDueOffsetWorkingDays 截止日期偏移的最大工作日天数
+const maxDueOffsetWorkingDays = 250
+
+// occurrencePlan 一次计划触发经过节假日策略处理后的执行计划
+type occurrencePlan struct {
+	Skip        bool      // 是否跳过本次触发
+	EffectiveAt time.Time // 任务实际创建的时间 (顺延/提前后的时间)
+	Message     string    // 记录在触发记录中的说明
+}
+
+// validatePeriodicTaskPolicies 校验并规范化规则的节假日/请假/轮值策略
+func validatePeriodicTaskPolicies(pt *model.PeriodicTask) error {
+	if pt.HolidayPolicy == "" {
+		pt.HolidayPolicy = "none"
+	}
+	if pt.LeaveFallback == "" {
+		pt.LeaveFallback = "none"
+	}
+	if !validHolidayPolicies[pt.HolidayPolicy] {
+		return errors.New("invalid holiday policy")
+	}
+	if !validLeaveFallbacks[pt.LeaveFallback] {
+		return errors.New("invalid leave fallback")
+	}
+	if pt.LeaveFallback == "backup" && pt.BackupAssigneeID == nil {
+		return errors.New("backup assignee is required when leave fallback is 'backup'")
+	}
+	if err := validateRotation(pt); err != nil {
+		return err
+	}
+
+	if pt.OverlapPolicy == "" {
+		pt.OverlapPolicy = "always"
+	}
+	if !validOverlapPolicies[pt.OverlapPolicy] {
+		return errors.New("invalid overlap policy")
+	}
+	// 顺延时按优先级阶梯提升优先级，不在阶梯上的优先级无法提升
+	if pt.OverlapPolicy == "roll_forward" {
+		if ladder := loadPriorityLadder(); priorityLadderIndex(ladder, pt.DefaultPriority) < 0 {
+			return fmt.Errorf("default priority must be one of %s when overlap policy is 'roll_forward'", strings.Join(ladder, ", "))
+		}
+	}
+
+	if pt.DueOffsetType == "" {
+		pt.DueOffsetType = "none"
+	}
+	if !validDueOffsetTypes[pt.DueOffsetType] {
+		return errors.New("invalid due offset type")
+	}
+	if pt.DueOffsetType != "working_days" {
+		pt.DueOffsetValue = 0
+	} else if pt.DueOffsetValue < 0 || pt.DueOffsetValue > maxDueOffsetWorkingDays {
+		return errors.New("due offset must be between 0 and 250 working days")
+	}
+	return nil
+}
+
+// computeRuleDueDate 根据规则的截止日期偏移，计算生成任务的截止日期 (按工作日日历，截止到当天结束)
+// 未配置偏移或计算失败时返回 nil
+func computeRuleDueDate(pt model.PeriodicTask, effectiveAt time.Time) *time.Time {
+	var dueDay time.Time
+	var err error
+	switch pt.DueOffsetType {
+	case "working_days":
+		dueDay, err = utils.AddWorkingDays(effectiveAt, pt.DueOffsetValue)
+	case "end_of_week":
+		// 一周以周日结束，取本周内最后一个工作日
+		daysUntilSunday := (7 - int(effectiveAt.Weekday())) % 7
+		dueDay, err = utils.LastWorkingDayBetween(effectiveAt, effectiveAt.AddDate(0, 0, daysUntilSunday))
+	case "end_of_month":
+		firstOfNextMonth := time.Date(effectiveAt.Year(), effectiveAt.Month()+1, 1, 0, 0, 0, 0, effectiveAt.Location())
+		dueDay, err = utils.LastWorkingDayBetween(effectiveAt, firstOfNextMonth.AddDate(0, 0, -1))
+	default:
+		return nil
+	}
+	if err != nil {
+		log.Printf("Failed to compute due date for periodic task '%s': %v", pt.Title, err)
+		return nil
+	}
+
+	dueDate := time.Date(dueDay.Year(), dueDay.Month(), dueDay.Day(), 23, 59, 59, 0, dueDay.Location())
+	return &dueDate
+}
+
+// planRuleOccurrence 根据规则的节假日策略，决定一次触发是跳过、顺延还是照常执行
+// 'previous_working_day' 的提前创建由 processShiftedPeriodicRuns 负责；走到这里说明提前的时机已经错过，只能当天创建
+func planRuleOccurrence(pt model.PeriodicTask, scheduledAt time.Time) occurrencePlan {
+	plan := occurrencePlan{EffectiveAt: scheduledAt}
+	if paused, ok := pausedPlan(pt, scheduledAt); ok {
+		return paused
+	}
+	if pt.HolidayPolicy == "" || pt.HolidayPolicy == "none" {
+		return plan
+	}
+
+	onHoliday, err := utils.IsHoliday(scheduledAt)
+	if err != nil {
+		log.Printf("Failed to check holidays for periodic task '%s': %v", pt.Title, err)
+		return plan
+	}
+	if !onHoliday {
+		return plan
+	}
+
+	switch pt.HolidayPolicy {
+	case "skip":
+		plan.Skip = true
+		plan.Message = "occurrence falls on a holiday"
+	case "next_working_day":
+		shifted, err := utils.ShiftToWorkingDay(scheduledAt, 1)
+		if err != nil {
+			plan.Message = "occurrence falls on a holiday, but no next working day was found"
+			return plan
+		}
+		plan.EffectiveAt = shifted
+		plan.Message = "occurrence falls on a holiday, shifted to next working day"
+	case "previous_working_day":
+		plan.Message = "occurrence falls on a holiday, previous working day has already passed"
+	}
+	return plan
+}
+
+// pausedPlan 规则暂停期间的触发一律跳过，到达暂停截止时间后自动恢复
+func pausedPlan(pt model.PeriodicTask, scheduledAt time.Time) (occurrencePlan, bool) {
+	if pt.PausedUntil == nil || !scheduledAt.Before(*pt.PausedUntil) {
+		return occurrencePlan{}, false
+	}
+	return occurrencePlan{
+		Skip:        true,
+		EffectiveAt: scheduledAt,
+		Message:     "rule is paused until " + pt.PausedUntil.Format(time.RFC3339),
+	}, true
+}
+
+// previewRuleOccurrence 与 planRuleOccurrence 相同，但用于尚未到来的触发：
+// 'previous_working_day' 会展示提前后的创建时间，而不是"已错过"
+func previewRuleOccurrence(pt model.PeriodicTask, scheduledAt time.Time) occurrencePlan {
+	if pt.HolidayPolicy != "previous_working_day" {
+		return planRuleOccurrence(pt, scheduledAt)
+	}
+	if paused, ok := pausedPlan(pt, scheduledAt); ok {
+		return paused
+	}
+
+	plan := occurrencePlan{EffectiveAt: scheduledAt}
+	onHoliday, err := utils.IsHoliday(scheduledAt)
+	if err != nil || !onHoliday {
+		return plan
+	}
+	if shifted, err := utils.ShiftToWorkingDay(scheduledAt, -1); err == nil {
+		plan.EffectiveAt = shifted
+		plan.Message = "occurrence falls on a holiday, moved to previous working day"
+	}
+	return plan
+}
+
+// ruleAssignment 一次触发最终确定的负责人
+type ruleAssignment struct {
+	AssigneeID      *uuid.UUID
+	Note            string
+	RotationPointer *int // 推进后的轮值指针，任务创建成功后才持久化 (nil 表示不变)
+}
+
+// resolveRuleAssignee 确定任务的负责人：先按轮值策略选出候选人 (未配置轮值时为默认负责人)，
+// 候选人在当天请假时，再按规则的请假策略改派给备岗人员或放入任务池
+func resolveRuleAssignee(pt model.PeriodicTask, day time.Time) ruleAssignment {
+	assignment := ruleAssignment{AssigneeID: pt.DefaultAssigneeID}
+	if pt.RotationStrategy != "" && pt.RotationStrategy != "none" {
+		assignment.AssigneeID, assignment.RotationPointer, assignment.Note = selectRotationAssignee(pt, day)
+	}
+	if assignment.AssigneeID == nil || pt.LeaveFallback == "" || pt.LeaveFallback == "none" {
+		return assignment
+	}
+
+	onLeave, err := utils.IsUserOnLeave(*assignment.AssigneeID, day)
+	if err != nil {
+		log.Printf("Failed to check leaves for periodic task '%s': %v", pt.Title, err)
+		return assignment
+	}
+	if !onLeave {
+		return assignment
+	}
+
+	if pt.LeaveFallback == "backup" && pt.BackupAssigneeID != nil {
+		backupOnLeave, err := utils.IsUserOnLeave(*pt.BackupAssigneeID, day)
+		if err == nil && !backupOnLeave {
+			assignment.AssigneeID = pt.BackupAssigneeID
+			assignment.Note = joinRunNotes(assignment.Note, "assignee is on leave, assigned to backup assignee")
+			return assignment
+		}
+		assignment.AssigneeID = nil
+		assignment.Note = joinRunNotes(assignment.Note, "assignee and backup assignee are on leave, released to pool")
+		return assignment
+	}
+	assignment.AssigneeID = nil
+	assignment.Note = joinRunNotes(assignment.Note, "assignee is on leave, released to pool")
+	return assignment
+}
+
+// processShiftedPeriodicRuns 处理因节假日需要顺延或提前的触发 (仅在 leader 上每分钟执行一次)
+func processShiftedPeriodicRuns() {
+	now := time.Now()
+	processDeferredPeriodicRuns(now)
+	processPreviousWorkingDayRuns(now)
+}
+
+// processDeferredPeriodicRuns 为已到顺延时间的触发创建任务
+func processDeferredPeriodicRuns(now time.Time) {
+	runs, err := repository.ListDueDeferredPeriodicTaskRuns(now)
+	if err != nil {
+		log.Printf("Error listing deferred periodic task runs: %v", err)
+		return
+	}
+
+	for _, run := range runs {
+		claimed, err := repository.ClaimDeferredPeriodicTaskRun(run.ID)
+		if err != nil || !claimed {
+			continue
+		}
+		pt, err := repository.FindPeriodicTaskByID(run.PeriodicTaskID)
+		if err != nil || !pt.IsActive {
+			finishPeriodicTaskRun(run, "skipped", nil, "rule was disabled before the deferred run")
+			continue
+		}
+		executePeriodicTaskRun(pt, run, *run.EffectiveAt, run.Message)
+	}
+}
+
+// processPreviousWorkingDayRuns 对 'previous_working_day' 规则，提前在上一个工作日创建落在节假日的触发
+// 提前创建的触发会被登记，节假日当天调度器再次触发时会因唯一约束而跳过
+func processPreviousWorkingDayRuns(now time.Time) {
+	periodicTasks, err := repository.ListAllActivePeriodicTasks()
+	if err != nil {
+		log.Printf("Error listing periodic tasks for holiday shift: %v", err)
+		return
+	}
+
+	horizon := now.AddDate(0, 0, holidayShiftLookaheadDays)
+	var holidays map[string]bool
+	for _, pt := range periodicTasks {
+		if pt.HolidayPolicy != "previous_working_day" {
+			continue
+		}
+		// 节假日只在确实存在此类规则时查询一次
+		if holidays == nil {
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			if holidays, err = repository.GetHolidaysInRange(today, horizon); err != nil {
+				log.Printf("Error listing holidays for holiday shift: %v", err)
+				return
+			}
+		}
+		occurrences, err := holidayOccurrences(pt, holidays, now, horizon)
+		if err != nil {
+			continue
+		}
+		for _, occurrence := range occurrences {
+			shifted, err := utils.ShiftToWorkingDay(occurrence, -1)
+			if err != nil || shifted.After(now) {
+				continue
+			}
+
+			run := model.PeriodicTaskRun{
+				PeriodicTaskID: pt.ID,
+				ScheduledAt:    occurrence,
+				TriggerType:    "holiday_shift",
+				Status:         "running",
+				EffectiveAt:    &shifted,
+			}
+			claimed, err := repository.ClaimPeriodicTaskRun(&run)
+			if err != nil || !claimed {
+				continue
+			}
+			log.Printf("Creating periodic task '%s' early: occurrence at %s falls on a holiday", pt.Title, occurrence.Format(time.RFC3339))
+			executePeriodicTaskRun(pt, run, shifted, "occurrence falls on a holiday, moved to previous working day")
+		}
+	}
+}
+
+// holidayOccurrences 按节假日逐天列出规则在 (now, horizon] 范围内落在节假日的全部触发
+// 只计算节假日当天的触发，高频规则 (如每分钟) 也不会遗漏或遍历整个窗口
+func holidayOccurrences(pt model.PeriodicTask, holidays map[string]bool, now, horizon time.Time) ([]time.Time, error) {
+	schedule, err := parseRuleSchedule(pt)
+	if err != nil {
+		return nil, err
+	}
+	loc := ruleLocation(pt)
+
+	days := make([]string, 0, len(holidays))
+	for day := range holidays {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	var occurrences []time.Time
+	for _, day := range days {
+		dayStart, err := time.ParseInLocation("2006-01-02", day, loc)
+		if err != nil {
+			continue
+		}
+		dayEnd := dayStart.AddDate(0, 0, 1)
+
+		// Next 返回严格晚于给定时间的触发，因此从当天零点 (或规则开始时间) 的前一秒算起
+		from := dayStart.Add(-time.Second)
+		if pt.StartDate != nil && pt.StartDate.Add(-time.Second).After(from) {
+			from = pt.StartDate.Add(-time.Second)
+		}
+		if now.After(from) {
+			from = now
+		}
+		for next := schedule.Next(from); !next.IsZero() && next.Before(dayEnd) && !next.After(horizon); next = schedule.Next(next) {
+			if pt.EndDate != nil && next.After(*pt.EndDate) {
+				break
+			}
+			occurrences = append(occurrences, next)
+		}
+	}
+	return occurrences, nil
+}
+
+// findOpenRuleInstance 按规则的重叠策略，查找上一次生成且仍未完成的任务；策略为 'always' 或没有未完成任务时返回 nil
+func findOpenRuleInstance(pt model.PeriodicTask) *model.Task {
+	if pt.OverlapPolicy == "" || pt.OverlapPolicy == "always" {
+		return nil
+	}
+	task, err := repository.FindOpenTaskFromPeriodicRule(pt.ID, openPeriodicTaskStatuses)
+	if err != nil {
+		return nil
+	}
+	return &task
+}
+
+// rollForwardOpenInstance 将仍未完成的旧任务顺延到本次触发：按本次触发重新计算截止日期，并把优先级提升一级
+func rollForwardOpenInstance(pt model.PeriodicTask, task model.Task, effectiveAt time.Time) (string, error) {
+	updates := map[string]interface{}{}
+	message := fmt.Sprintf("previous instance (task %d) is still %s, rolled forward", task.ID, task.Status)
+
+	if dueDate := computeRuleDueDate(pt, effectiveAt); dueDate != nil {
+		updates["due_date"] = *dueDate
+		message += ", due date moved to " + dueDate.Format("2006-01-02")
+	}
+	if priority, bumped := bumpPriority(task.Priority); bumped {
+		updates["priority"] = priority
+		message += ", priority raised to " + priority
+	}
+	if len(updates) == 0 {
+		return message, nil
+	}
+	if err := repository.UpdateTaskFields(task.ID, updates); err != nil {
+		return "", err
+	}
+	return message, nil
+}
+
+// bumpPriority 返回高一级的优先级；已是最高级或无法识别时返回原值和 false
+func bumpPriority(priority string) (string, bool) {
+	ladder := loadPriorityLadder()
+	if i := priorityLadderIndex(ladder, priority); i >= 0 && i+1 < len(ladder) {
+		return ladder[i+1], true
+	}
+	return priority, false
+}
+
+// loadPriorityLadder 读取系统配置的优先级阶梯 (从低到高)
+func loadPriorityLadder() []string {
+	return getConfigList("periodic_priority_ladder", defaultPriorityLadder)
+}
+
+// priorityLadderIndex 返回优先级在阶梯中的位置，不在阶梯上时返回 -1
+func priorityLadderIndex(ladder []string, priority string) int {
+	for i, p := range ladder {
+		if p == priority {
+			return i
+		}
+	}
+	return -1
+}
+
+// joinRunNotes 合并触发记录中的多条说明
+func joinRunNotes(notes ...string) string {
+	var parts []string
+	for _, note := range notes {
+		if note != "" {
+			parts = append(parts, note)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,308 @@
+// internal/service/periodic_preview_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+	"gotasksys/pkg/rrule"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPreviewCount = 10
+	maxPreviewCount     = 100
+)
+
+// SchedulePreview 计划任务规则的触发预览
+type SchedulePreview struct {
+	CronExpression string      `json:"cron_expression,omitempty"`
+	RRule          string      `json:"rrule,omitempty"`
+	TimeZone       string      `json:"time_zone"`
+	Description    string      `json:"description"`
+	NextRuns       []time.Time `json:"next_runs"`
+	PausedUntil    *time.Time  `json:"paused_until,omitempty"` // 规则暂停期间的触发会被跳过
+}
+
+// PeriodicTaskDryRun 规则下一次触发时将要创建的任务 (不会写入数据库)
+type PeriodicTaskDryRun struct {
+	ScheduledAt  time.Time   `json:"scheduled_at"`
+	EffectiveAt  time.Time   `json:"effective_at"`           // 经节假日策略顺延/提前后的实际创建时间
+	Skipped      bool        `json:"skipped"`                // 本次触发是否会被暂停/节假日/重叠策略跳过
+	OpenTaskID   *uint       `json:"open_task_id,omitempty"` // 重叠策略命中的未完成旧任务
+	Message      string      `json:"message,omitempty"`
+	Task         *model.Task `json:"task,omitempty"`
+	TaskTypeName string      `json:"task_type_name,omitempty"`
+}
+
+// PreviewPeriodicScheduleService 计算一条规则接下来 count 次的触发时间
+// 与调度器使用同一个解析器，并遵守规则的开始/结束时间；规则可以尚未保存
+func PreviewPeriodicScheduleService(pt model.PeriodicTask, count int) (SchedulePreview, error) {
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	if count > maxPreviewCount {
+		count = maxPreviewCount
+	}
+
+	if err := validateRuleSchedule(pt); err != nil {
+		return SchedulePreview{}, err
+	}
+	runs, err := nextRuleOccurrences(pt, time.Now(), count)
+	if err != nil {
+		return SchedulePreview{}, err
+	}
+	return SchedulePreview{
+		CronExpression: pt.CronExpression,
+		RRule:          pt.RRule,
+		TimeZone:       ruleLocation(pt).String(),
+		Description:    describeRuleSchedule(pt),
+		NextRuns:       runs,
+		PausedUntil:    pt.PausedUntil,
+	}, nil
+}
+
+// PreviewPeriodicTaskService 预览一条已保存规则的触发时间
+func PreviewPeriodicTaskService(id uuid.UUID, count int) (SchedulePreview, error) {
+	pt, err := repository.FindPeriodicTaskByID(id)
+	if err != nil {
+		return SchedulePreview{}, errors.New("periodic task not found")
+	}
+	return PreviewPeriodicScheduleService(pt, count)
+}
+
+// DryRunPeriodicTaskService 展示规则下一次触发时会创建的任务，不登记触发、不占用任务编号
+func DryRunPeriodicTaskService(id uuid.UUID) (PeriodicTaskDryRun, error) {
+	pt, err := repository.FindPeriodicTaskByID(id)
+	if err != nil {
+		return PeriodicTaskDryRun{}, errors.New("periodic task not found")
+	}
+
+	runs, err := nextRuleOccurrences(pt, time.Now(), 1)
+	if err != nil {
+		return PeriodicTaskDryRun{}, err
+	}
+	if len(runs) == 0 {
+		return PeriodicTaskDryRun{}, errors.New("periodic task has no upcoming runs")
+	}
+
+	// 与实际触发使用相同的节假日/请假策略
+	plan := previewRuleOccurrence(pt, runs[0])
+	result := PeriodicTaskDryRun{
+		ScheduledAt: runs[0],
+		EffectiveAt: plan.EffectiveAt,
+		Skipped:     plan.Skip,
+		Message:     plan.Message,
+	}
+	if plan.Skip {
+		return result, nil
+	}
+	if openTask := findOpenRuleInstance(pt); openTask != nil {
+		// 旧任务仍未完成：跳过，或顺延旧任务 (都不会创建新任务)
+		result.OpenTaskID = &openTask.ID
+		result.Skipped = pt.OverlapPolicy == "skip_if_open"
+		result.Message = joinRunNotes(plan.Message, fmt.Sprintf("previous instance (task %d) is still %s, overlap policy '%s' applies", openTask.ID, openTask.Status, pt.OverlapPolicy))
+		return result, nil
+	}
+	task := buildTaskFromRule(pt, plan.EffectiveAt)
+	assignment := resolveRuleAssignee(pt, plan.EffectiveAt)
+	task.AssigneeID = assignment.AssigneeID
+	result.Task = &task
+	result.Message = joinRunNotes(plan.Message, assignment.Note)
+
+	// 补充负责人和任务类型信息，方便管理者直接核对
+	if creator, err := repository.FindUserByID(pt.CreatedByID); err == nil {
+		result.Task.Creator = creator
+	}
+	if result.Task.AssigneeID != nil {
+		if assignee, err := repository.FindUserByID(*result.Task.AssigneeID); err == nil {
+			result.Task.Assignee = &assignee
+		}
+	}
+	if result.Task.TaskTypeID != nil {
+		if taskType, err := repository.FindTaskTypeByID(*result.Task.TaskTypeID); err == nil {
+			result.TaskTypeName = taskType.Name
+		}
+	}
+	return result, nil
+}
+
+// nextRuleOccurrences 计算规则在 from 之后的最多 count 次触发时间 (遵守开始/结束时间)
+func nextRuleOccurrences(pt model.PeriodicTask, from time.Time, count int) ([]time.Time, error) {
+	schedule, err := parseRuleSchedule(pt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Next 返回严格晚于给定时间的触发，因此从开始时间的前一秒算起，开始时间本身也可以触发
+	if pt.StartDate != nil && pt.StartDate.After(from) {
+		from = pt.StartDate.Add(-time.Second)
+	}
+
+	runs := make([]time.Time, 0, count)
+	for next := schedule.Next(from); !next.IsZero() && len(runs) < count; next = schedule.Next(next) {
+		if pt.EndDate != nil && next.After(*pt.EndDate) {
+			break
+		}
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
+
+// --- Cron 表达式描述 ---
+
+var cronMonthNames = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+var cronWeekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// describeRuleSchedule 返回规则调度的可读描述 (Cron表达式或 RRULE)
+func describeRuleSchedule(pt model.PeriodicTask) string {
+	if pt.RRule != "" {
+		if schedule, err := parseRuleSchedule(pt); err == nil {
+			if rule, ok := schedule.(*rrule.Rule); ok {
+				return rule.Describe()
+			}
+		}
+		return pt.RRule
+	}
+	return describeCronExpression(pt.CronExpression)
+}
+
+// describeCronExpression 将Cron表达式转换为便于阅读的英文描述，如 "At 09:00, on Monday through Friday"
+// 无法识别的写法会原样返回表达式本身
+func describeCronExpression(expr string) string {
+	expr = strings.TrimSpace(expr)
+	switch expr {
+	case "@yearly", "@annually":
+		return "At 00:00 on January 1st"
+	case "@monthly":
+		return "At 00:00 on day 1 of the month"
+	case "@weekly":
+		return "At 00:00 on Sunday"
+	case "@daily", "@midnight":
+		return "At 00:00 every day"
+	case "@hourly":
+		return "At the start of every hour"
+	}
+	if strings.HasPrefix(expr, "@every ") {
+		return "Every " + strings.TrimSpace(strings.TrimPrefix(expr, "@every "))
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) > 0 && cronTimeZonePrefix(expr) != "" {
+		fields = fields[1:]
+	}
+	if len(fields) == 5 {
+		fields = append([]string{"0"}, fields...)
+	}
+	if len(fields) != 6 {
+		return expr
+	}
+	second, minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5]
+
+	var parts []string
+	if isPlainNumber(second) && isPlainNumber(minute) && isPlainNumber(hour) {
+		h, _ := strconv.Atoi(hour)
+		m, _ := strconv.Atoi(minute)
+		s, _ := strconv.Atoi(second)
+		if s == 0 {
+			parts = append(parts, fmt.Sprintf("At %02d:%02d", h, m))
+		} else {
+			parts = append(parts, fmt.Sprintf("At %02d:%02d:%02d", h, m, s))
+		}
+	} else {
+		if second != "0" {
+			parts = append(parts, describeCronField(second, "second", "at second", nil))
+		}
+		parts = append(parts, describeCronField(minute, "minute", "at minute", nil))
+		if !isWildcard(hour) {
+			parts = append(parts, describeCronField(hour, "hour", "past hour", nil))
+		}
+	}
+
+	if !isWildcard(dom) {
+		parts = append(parts, describeCronField(dom, "day", "on day", nil)+" of the month")
+	}
+	if !isWildcard(month) {
+		parts = append(parts, describeCronField(month, "month", "in", cronMonthNames))
+	}
+	if !isWildcard(dow) {
+		parts = append(parts, describeCronField(dow, "day of the week", "on", cronWeekdayNames))
+	}
+	if isWildcard(dom) && isWildcard(month) && isWildcard(dow) && isPlainNumber(hour) {
+		parts = append(parts, "every day")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeCronField 描述单个Cron字段，prefix 用于引导具体取值 (如 "at minute 30")；
+// names 不为空时，数值会被替换为名称 (月份、星期)
+func describeCronField(field, unit, prefix string, names []string) string {
+	if isWildcard(field) {
+		return "every " + unit
+	}
+
+	var descs []string
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, ""
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			rangePart, step = item[:idx], item[idx+1:]
+		}
+
+		var rangeDesc string
+		switch {
+		case isWildcard(rangePart):
+			rangeDesc = ""
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			rangeDesc = cronValueName(bounds[0], names) + " through " + cronValueName(bounds[1], names)
+		default:
+			rangeDesc = cronValueName(rangePart, names)
+		}
+
+		switch {
+		case step != "" && rangeDesc == "":
+			descs = append(descs, "every "+step+" "+unit+"s")
+		case step != "":
+			descs = append(descs, "every "+step+" "+unit+"s from "+rangeDesc)
+		default:
+			descs = append(descs, rangeDesc)
+		}
+	}
+
+	joined := strings.Join(descs, ", ")
+	if strings.HasPrefix(joined, "every ") {
+		return joined
+	}
+	return prefix + " " + joined
+}
+
+// cronValueName 将数值或缩写转换为名称 (如 1 / MON -> Monday)，无法识别的值原样返回
+func cronValueName(value string, names []string) string {
+	if names == nil {
+		return value
+	}
+	for _, name := range names {
+		if len(name) >= 3 && strings.EqualFold(value, name[:3]) {
+			return name
+		}
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n < len(names) {
+		return names[n]
+	}
+	return value
+}
+
+func isWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+func isPlainNumber(field string) bool {
+	_, err := strconv.Atoi(field)
+	return err == nil
+}
@@ -0,0 +1,156 @@
+// internal/service/periodic_rotation_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+	"gotasksys/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+var validRotationStrategies = map[string]bool{"none": true, "round_robin": true, "skip_on_leave": true, "least_loaded": true}
+
+// validateRotation 校验规则的轮值策略和轮值池
+func validateRotation(pt *model.PeriodicTask) error {
+	if pt.RotationStrategy == "" {
+		pt.RotationStrategy = "none"
+	}
+	if !validRotationStrategies[pt.RotationStrategy] {
+		return errors.New("invalid rotation strategy")
+	}
+	if pt.RotationStrategy == "none" {
+		pt.RotationUserIDs = nil
+		return nil
+	}
+	if len(pt.RotationUserIDs) == 0 {
+		return errors.New("rotation pool must have at least one member")
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	for _, userID := range pt.RotationUserIDs {
+		if seen[userID] {
+			return errors.New("rotation pool contains duplicate members")
+		}
+		seen[userID] = true
+	}
+	users, err := repository.FindUsersByIDs(pt.RotationUserIDs)
+	if err != nil {
+		return err
+	}
+	if len(users) != len(pt.RotationUserIDs) {
+		return errors.New("rotation pool contains unknown users")
+	}
+	return nil
+}
+
+// attachRotationMembers 为规则列表填充轮值池成员
+func attachRotationMembers(pts []model.PeriodicTask) error {
+	ids := make([]uuid.UUID, 0, len(pts))
+	for _, pt := range pts {
+		ids = append(ids, pt.ID)
+	}
+	members, err := repository.ListRotationMembers(ids)
+	if err != nil {
+		return err
+	}
+
+	membersByRule := make(map[uuid.UUID][]uuid.UUID)
+	for _, member := range members {
+		membersByRule[member.PeriodicTaskID] = append(membersByRule[member.PeriodicTaskID], member.UserID)
+	}
+	for i := range pts {
+		pts[i].RotationUserIDs = membersByRule[pts[i].ID]
+	}
+	return nil
+}
+
+// selectRotationAssignee 按规则的轮值策略从轮值池中选出本次的负责人
+// 返回选中的成员 (nil 表示放入任务池)、推进后的轮值指针和说明；不会持久化指针
+func selectRotationAssignee(pt model.PeriodicTask, day time.Time) (*uuid.UUID, *int, string) {
+	members, err := repository.ListRotationMembers([]uuid.UUID{pt.ID})
+	if err != nil || len(members) == 0 {
+		if err != nil {
+			log.Printf("Failed to load rotation pool for periodic task '%s': %v", pt.Title, err)
+		}
+		return pt.DefaultAssigneeID, nil, ""
+	}
+
+	// 调度器中缓存的规则可能是旧的，以数据库中的轮值指针为准
+	pointer := pt.RotationPointer
+	if latest, err := repository.FindPeriodicTaskByID(pt.ID); err == nil {
+		pointer = latest.RotationPointer
+	}
+	count := len(members)
+	start := ((pointer % count) + count) % count
+
+	switch pt.RotationStrategy {
+	case "round_robin":
+		next := (start + 1) % count
+		return &members[start].UserID, &next, ""
+
+	case "skip_on_leave":
+		for i := 0; i < count; i++ {
+			idx := (start + i) % count
+			onLeave, err := utils.IsUserOnLeave(members[idx].UserID, day)
+			if err == nil && onLeave {
+				continue
+			}
+			next := (idx + 1) % count
+			note := ""
+			if i > 0 {
+				note = fmt.Sprintf("rotation skipped %d member(s) on leave", i)
+			}
+			return &members[idx].UserID, &next, note
+		}
+		next := (start + 1) % count
+		return nil, &next, "all rotation members are on leave, released to pool"
+
+	case "least_loaded":
+		userIDs := make([]uuid.UUID, 0, count)
+		for _, member := range members {
+			userIDs = append(userIDs, member.UserID)
+		}
+		users, err := repository.FindUsersByIDs(userIDs)
+		if err != nil {
+			log.Printf("Failed to load rotation members for periodic task '%s': %v", pt.Title, err)
+			return pt.DefaultAssigneeID, nil, ""
+		}
+		usersByID := make(map[uuid.UUID]model.User)
+		for _, user := range users {
+			usersByID[user.ID] = user
+		}
+
+		// 从指针位置开始比较，负载相同时按轮换顺序优先
+		chosen, chosenLoad := -1, 0.0
+		for i := 0; i < count; i++ {
+			idx := (start + i) % count
+			user, ok := usersByID[members[idx].UserID]
+			if !ok {
+				continue
+			}
+			if onLeave, err := utils.IsUserOnLeave(user.ID, day); err == nil && onLeave {
+				continue
+			}
+			load, err := getUserLoadPercentage(user)
+			if err != nil {
+				continue
+			}
+			if chosen < 0 || load < chosenLoad {
+				chosen, chosenLoad = idx, load
+			}
+		}
+		if chosen < 0 {
+			next := (start + 1) % count
+			return nil, &next, "no available rotation member, released to pool"
+		}
+		next := (chosen + 1) % count
+		return &members[chosen].UserID, &next, fmt.Sprintf("least loaded rotation member (load %.0f%%)", chosenLoad)
+	}
+	return pt.DefaultAssigneeID, nil, ""
+}
@@ -5,25 +5,38 @@ import (
 	"errors"
 	"gotasksys/internal/model"
 	"gotasksys/internal/repository"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/robfig/cron/v3"
 )
 
 func ListPeriodicTasksService() ([]model.PeriodicTask, error) {
-	return repository.ListPeriodicTasks()
+	pts, err := repository.ListPeriodicTasks()
+	if err != nil {
+		return nil, err
+	}
+	if err := attachRotationMembers(pts); err != nil {
+		return nil, err
+	}
+	return pts, nil
 }
 
 func CreatePeriodicTaskService(input model.PeriodicTask, creatorID uuid.UUID) (model.PeriodicTask, error) {
-	// 校验Cron表达式格式是否正确
-	if _, err := cron.ParseStandard(input.CronExpression); err != nil {
-		return model.PeriodicTask{}, errors.New("invalid cron expression format")
+	// 校验调度规则 (Cron表达式或 RRULE) 是否正确，使用与调度器相同的解析逻辑
+	if err := validateRuleSchedule(input); err != nil {
+		return model.PeriodicTask{}, err
+	}
+	if err := validatePeriodicTaskPolicies(&input); err != nil {
+		return model.PeriodicTask{}, err
 	}
 
 	input.CreatedByID = creatorID
 	if err := repository.CreatePeriodicTask(&input); err != nil {
 		return model.PeriodicTask{}, err
 	}
+	if err := repository.ReplaceRotationMembers(input.ID, input.RotationUserIDs); err != nil {
+		return model.PeriodicTask{}, err
+	}
 	if input.IsActive {
 		AddJob(input)
 	}
@@ -31,8 +44,11 @@ func CreatePeriodicTaskService(input model.PeriodicTask, creatorID uuid.UUID) (m
 }
 
 func UpdatePeriodicTaskService(id uuid.UUID, input model.PeriodicTask) (model.PeriodicTask, error) {
-	if _, err := cron.ParseStandard(input.CronExpression); err != nil {
-		return model.PeriodicTask{}, errors.New("invalid cron expression format")
+	if err := validateRuleSchedule(input); err != nil {
+		return model.PeriodicTask{}, err
+	}
+	if err := validatePeriodicTaskPolicies(&input); err != nil {
+		return model.PeriodicTask{}, err
 	}
 	pt, err := repository.FindPeriodicTaskByID(id)
 	if err != nil {
@@ -41,14 +57,29 @@ func UpdatePeriodicTaskService(id uuid.UUID, input model.PeriodicTask) (model.Pe
 	pt.Title = input.Title
 	pt.Description = input.Description
 	pt.CronExpression = input.CronExpression
+	pt.RRule = input.RRule
+	pt.TimeZone = input.TimeZone
 	pt.DefaultAssigneeID = input.DefaultAssigneeID
 	pt.DefaultEffort = input.DefaultEffort
 	pt.DefaultPriority = input.DefaultPriority
 	pt.DefaultTaskTypeID = input.DefaultTaskTypeID
+	pt.StartDate = input.StartDate
+	pt.EndDate = input.EndDate
+	pt.HolidayPolicy = input.HolidayPolicy
+	pt.LeaveFallback = input.LeaveFallback
+	pt.BackupAssigneeID = input.BackupAssigneeID
+	pt.DueOffsetType = input.DueOffsetType
+	pt.DueOffsetValue = input.DueOffsetValue
+	pt.RotationStrategy = input.RotationStrategy
+	pt.OverlapPolicy = input.OverlapPolicy
+	pt.RotationUserIDs = input.RotationUserIDs
 
 	if err := repository.UpdatePeriodicTask(&pt); err != nil {
 		return model.PeriodicTask{}, err
 	}
+	if err := repository.ReplaceRotationMembers(pt.ID, pt.RotationUserIDs); err != nil {
+		return model.PeriodicTask{}, err
+	}
 	RemoveJob(id.String())
 	if pt.IsActive {
 		AddJob(pt)
@@ -77,3 +108,54 @@ func TogglePeriodicTaskService(id uuid.UUID, isActive bool) error {
 	}
 	return nil
 }
+
+// RunPeriodicTaskNowService 立即触发一次计划任务，与定时作业走相同的创建流程，触发记录标记为 'manual'
+func RunPeriodicTaskNowService(id uuid.UUID) (model.PeriodicTaskRun, error) {
+	pt, err := repository.FindPeriodicTaskByID(id)
+	if err != nil {
+		return model.PeriodicTaskRun{}, errors.New("periodic task not found")
+	}
+
+	scheduledAt := time.Now().Truncate(time.Second).In(ruleLocation(pt))
+	createTaskFromRule(pt, scheduledAt, "manual")
+
+	run, err := repository.FindPeriodicTaskRun(pt.ID, scheduledAt)
+	if err != nil {
+		return model.PeriodicTaskRun{}, errors.New("failed to record manual run")
+	}
+	if run.TriggerType != "manual" {
+		return model.PeriodicTaskRun{}, errors.New("an occurrence at this time has already been processed, please retry")
+	}
+	return run, nil
+}
+
+// PausePeriodicTaskService 暂停计划任务到指定时间，期间的触发都会被跳过，到期后自动恢复
+func PausePeriodicTaskService(id uuid.UUID, until time.Time) (model.PeriodicTask, error) {
+	if !until.After(time.Now()) {
+		return model.PeriodicTask{}, errors.New("pause end time must be in the future")
+	}
+	return setPeriodicTaskPause(id, &until)
+}
+
+// ResumePeriodicTaskService 立即恢复一个已暂停的计划任务
+func ResumePeriodicTaskService(id uuid.UUID) (model.PeriodicTask, error) {
+	return setPeriodicTaskPause(id, nil)
+}
+
+func setPeriodicTaskPause(id uuid.UUID, until *time.Time) (model.PeriodicTask, error) {
+	pt, err := repository.FindPeriodicTaskByID(id)
+	if err != nil {
+		return model.PeriodicTask{}, errors.New("periodic task not found")
+	}
+	pt.PausedUntil = until
+
+	if err := repository.UpdatePeriodicTask(&pt); err != nil {
+		return model.PeriodicTask{}, err
+	}
+	// 重新注册作业，让调度器使用最新的暂停设置
+	RemoveJob(id.String())
+	if pt.IsActive {
+		AddJob(pt)
+	}
+	return pt, nil
+}
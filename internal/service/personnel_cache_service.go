@@ -0,0 +1,84 @@
+// internal/service/personnel_cache_service.go
+package service
+
+import (
+	"sync"
+	"time"
+
+	"gotasksys/internal/config"
+
+	"gorm.io/gorm"
+)
+
+// personnelCacheTables 这些表发生写入时，人员看板缓存立即失效
+var personnelCacheTables = map[string]bool{
+	"tasks":                   true,
+	"leaves":                  true,
+	"holidays":                true,
+	"users":                   true,
+	"system_configs":          true,
+	"status_light_thresholds": true,
+}
+
+var (
+	personnelCacheMu         sync.Mutex
+	personnelCacheStatuses   []PersonnelStatus
+	personnelCacheExpiresAt  time.Time
+	personnelCacheGeneration uint64 // 每次失效时递增，避免把失效前开始计算的旧结果写入缓存
+)
+
+// InitPersonnelStatusCache 注册 GORM 回调，在相关表发生增删改时清空人员看板缓存
+// 缓存只在当前实例内有效，其他实例的写入依靠较短的缓存时长 (personnel_status_cache_seconds) 兜底
+func InitPersonnelStatusCache() {
+	callback := config.DB.Callback()
+	_ = callback.Create().After("gorm:create").Register("personnel_cache:after_create", invalidatePersonnelCacheCallback)
+	_ = callback.Update().After("gorm:update").Register("personnel_cache:after_update", invalidatePersonnelCacheCallback)
+	_ = callback.Delete().After("gorm:delete").Register("personnel_cache:after_delete", invalidatePersonnelCacheCallback)
+}
+
+// invalidatePersonnelCacheCallback 写入成功且涉及相关表时清空缓存
+func invalidatePersonnelCacheCallback(db *gorm.DB) {
+	if db.Error != nil || !personnelCacheTables[db.Statement.Table] {
+		return
+	}
+	invalidatePersonnelStatusCache()
+}
+
+// invalidatePersonnelStatusCache 清空人员看板缓存
+func invalidatePersonnelStatusCache() {
+	personnelCacheMu.Lock()
+	defer personnelCacheMu.Unlock()
+	personnelCacheStatuses = nil
+	personnelCacheExpiresAt = time.Time{}
+	personnelCacheGeneration++
+}
+
+// getCachedPersonnelStatuses 返回缓存中的看板数据，缓存过期或被禁用 (缓存时长为 0) 时重新计算
+func getCachedPersonnelStatuses() ([]PersonnelStatus, error) {
+	ttl := time.Duration(getConfigInt("personnel_status_cache_seconds", 30)) * time.Second
+	if ttl <= 0 {
+		return computePersonnelStatuses()
+	}
+
+	personnelCacheMu.Lock()
+	if personnelCacheStatuses != nil && time.Now().Before(personnelCacheExpiresAt) {
+		statuses := append([]PersonnelStatus(nil), personnelCacheStatuses...)
+		personnelCacheMu.Unlock()
+		return statuses, nil
+	}
+	generation := personnelCacheGeneration
+	personnelCacheMu.Unlock()
+
+	statuses, err := computePersonnelStatuses()
+	if err != nil {
+		return nil, err
+	}
+
+	personnelCacheMu.Lock()
+	if generation == personnelCacheGeneration {
+		personnelCacheStatuses = statuses
+		personnelCacheExpiresAt = time.Now().Add(ttl)
+	}
+	personnelCacheMu.Unlock()
+	return append([]PersonnelStatus(nil), statuses...), nil
+}
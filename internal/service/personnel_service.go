@@ -4,11 +4,11 @@ package service
 import (
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"gotasksys/internal/model"
 	"gotasksys/internal/repository"
-	"gotasksys/pkg/utils"
 
 	"github.com/google/uuid"
 )
@@ -33,6 +33,7 @@ type PersonnelStatus struct {
 	LoadPercentage     float64                `json:"load_percentage"`
 	StatusLight        string                 `json:"status_light"`
 	HasOverdueTask     bool                   `json:"has_overdue_task"`
+	OverdueTaskCount   int                    `json:"overdue_task_count"`
 	PerformanceMetrics *PerformanceMetricsDto `json:"performance_metrics,omitempty"`
 }
 
@@ -44,90 +45,107 @@ type TaskInfo struct {
 
 // --- 核心服务函数 ---
 
-// GetPersonnelStatusService 获取所有人员的看板状态数据
-func GetPersonnelStatusService() ([]PersonnelStatus, error) {
+// GetPersonnelStatusService 获取所有人员的看板状态数据，team 不为空时只返回该团队的成员
+// 看板数据会在短时间内缓存，任务、请假或节假日发生变化时缓存立即失效
+func GetPersonnelStatusService(team string) ([]PersonnelStatus, error) {
+	statuses, err := getCachedPersonnelStatuses()
+	if err != nil {
+		return nil, err
+	}
+	if team = strings.TrimSpace(team); team == "" {
+		return statuses, nil
+	}
+
+	var filtered []PersonnelStatus
+	for _, status := range statuses {
+		if strings.EqualFold(strings.TrimSpace(status.User.Team), team) {
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered, nil
+}
+
+// computePersonnelStatuses 计算所有成员的看板数据
+// 任务、请假、节假日和评价汇总都通过少量集合查询一次性加载，之后全部在内存中计算，避免逐人逐任务查询数据库
+func computePersonnelStatuses() ([]PersonnelStatus, error) {
 	// 1. 获取所有需要展示在看板上的成员 (manager, executor)
 	members, err := repository.FindAllActiveMembers()
 	if err != nil {
 		return nil, err
 	}
+	memberIDs := make([]uuid.UUID, 0, len(members))
+	var executorIDs []uuid.UUID
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+		if member.Role == "executor" {
+			executorIDs = append(executorIDs, member.ID)
+		}
+	}
 
-	// 2. 一次性获取全局的每日工时配置，避免在循环中重复查询数据库
-	globalDailyHoursStr, err := repository.GetSystemConfigValueByKey("global_daily_work_hours")
+	// 2. 一次性获取全局的每日工时配置和状态灯阈值，避免在循环中重复查询数据库
+	globalDailyHours := getGlobalDailyHours()
+	lightSettings := loadStatusLightSettings()
+
+	// 3. 一次性获取所有成员正在进行的任务，并按负责人分组
+	allTasks, err := repository.FindInProgressTasksForUsers(memberIDs)
 	if err != nil {
-		globalDailyHoursStr = "8.0" // 如果获取失败，提供一个安全的默认值
+		return nil, err
+	}
+	tasksByUser := make(map[uuid.UUID][]model.Task)
+	for _, task := range allTasks {
+		if task.AssigneeID != nil {
+			tasksByUser[*task.AssigneeID] = append(tasksByUser[*task.AssigneeID], task)
+		}
 	}
-	globalDailyHours, _ := strconv.ParseFloat(globalDailyHoursStr, 64)
 
-	var statuses []PersonnelStatus
+	// 4. 日历数据 (节假日、请假) 只需覆盖从今天到最晚截止日期的范围
 	today := time.Now()
+	calendar, err := loadWorkCalendar(memberIDs, today, latestDueDate(allTasks, today))
+	if err != nil {
+		return nil, err
+	}
 
+	// 5. 一次性获取所有执行者的历史评价汇总
+	metricsByUser, err := repository.GetPerformanceMetricsForUsers(executorIDs, defaultScoreBasis())
+	if err != nil {
+		log.Printf("Failed to get performance metrics: %v", err)
+		metricsByUser = nil
+	}
+
+	statuses := make([]PersonnelStatus, 0, len(members))
 	for _, member := range members {
-		// 3. 获取该成员所有正在进行的任务
-		tasks, err := repository.FindInProgressTasksForUser(member.ID)
-		if err != nil {
-			log.Printf("Failed to get tasks for user %s: %v", member.Username, err)
-			continue // 查询单个用户任务失败，跳过该用户，继续处理下一个
-		}
+		tasks := tasksByUser[member.ID]
 
-		var dailyLoad float64
 		var activeTasks []TaskInfo
-		var hasOverdueTask bool
-
-		// 4. 【核心算法】循环计算每个任务对今天产生的负载
+		overdueCount := 0
 		for _, task := range tasks {
 			activeTasks = append(activeTasks, TaskInfo{ID: task.ID, Title: task.Title})
-
-			// 如果任务没有截止日期，其全部工时都算作“技术债务”，压在今天
-			if task.DueDate == nil {
-				dailyLoad += float64(task.Effort)
-				continue
-			}
-
-			// 如果任务已超期，其全部剩余工时也都算作今天的负载
-			if task.DueDate.Before(today) {
-				dailyLoad += float64(task.Effort)
-				hasOverdueTask = true
-				continue
-			}
-
-			// 对于未超期的任务，进行线性负载分配
-			// a. 调用我们强大的新工具，计算从今天到任务截止日期的“实际可用工作日”
-			availableDays, err := utils.CalculateAvailableWorkingDays(member.ID, today, *task.DueDate)
-			if err != nil {
-				log.Printf("Failed to calculate available days for task %d: %v", task.ID, err)
-				dailyLoad += float64(task.Effort) // 计算出错则全算
-				continue
-			}
-
-			if availableDays > 0 {
-				// b. 计算该任务每天需要分摊的工时
-				dailyEffort := float64(task.Effort) / float64(availableDays)
-				dailyLoad += dailyEffort
-			} else {
-				// 如果可用工作日为0（比如截止日期是今天，但今天是节假日或请假日），则全部工时压在今天
-				dailyLoad += float64(task.Effort)
+			if isTaskOverdue(task, today) {
+				overdueCount++
 			}
 		}
 
-		// 5. 【核心逻辑】确定该成员的“每日可用总工时”
-		dailyCapacity := globalDailyHours
-		if member.DailyCapacityHours != nil && *member.DailyCapacityHours > 0 {
-			dailyCapacity = *member.DailyCapacityHours // 个人配置优先
-		}
+		// 6. 【核心算法】计算所有任务对今天产生的负载
+		dailyLoad, hasOverdueTask := sumDailyLoad(tasks, today, calendar.availableFor(member.ID))
+
+		// 7. 【核心逻辑】确定该成员的“每日可用总工时”
+		dailyCapacity := resolveDailyCapacity(member, globalDailyHours)
 
-		// 6. 计算负载百分比和状态灯
+		// 8. 计算负载百分比和状态灯
 		loadPercentage := 0.0
 		if dailyCapacity > 0 {
 			loadPercentage = (dailyLoad / dailyCapacity) * 100
 		}
-		statusLight := calculateStatusLight(loadPercentage)
+		statusLight := calculateStatusLight(lightSettings.forUser(member), dailyLoad, loadPercentage)
 
-		// 7. 获取历史绩效评分
-		performanceMetrics, _ := GetUserPerformanceMetrics(member.ID)
+		// 9. 历史绩效评分 (只为 executor 计算)
+		var performanceMetrics *PerformanceMetricsDto
+		if member.Role == "executor" && metricsByUser != nil {
+			performanceMetrics = buildPerformanceMetricsDto(metricsByUser[member.ID])
+		}
 
-		// 8. 组装最终返回的完整数据
-		status := PersonnelStatus{
+		// 10. 组装最终返回的完整数据
+		statuses = append(statuses, PersonnelStatus{
 			User:               member,
 			InProgressTasks:    activeTasks,
 			CurrentLoadHours:   dailyLoad,
@@ -135,9 +153,9 @@ func GetPersonnelStatusService() ([]PersonnelStatus, error) {
 			LoadPercentage:     loadPercentage,
 			StatusLight:        statusLight,
 			HasOverdueTask:     hasOverdueTask,
+			OverdueTaskCount:   overdueCount,
 			PerformanceMetrics: performanceMetrics,
-		}
-		statuses = append(statuses, status)
+		})
 	}
 
 	return statuses, nil
@@ -145,17 +163,136 @@ func GetPersonnelStatusService() ([]PersonnelStatus, error) {
 
 // --- 辅助函数 ---
 
-// calculateStatusLight 修改为接收浮点数
-func calculateStatusLight(load float64) string {
-	if load == 0 {
-		return "idle"
-	} else if load > 0 && load <= 6.0 {
-		return "normal"
-	} else if load > 6.0 && load <= 8.0 {
-		return "busy"
-	} else {
-		return "overloaded"
+// getGlobalDailyHours 获取全局的每日工时配置
+func getGlobalDailyHours() float64 {
+	globalDailyHoursStr, err := repository.GetSystemConfigValueByKey("global_daily_work_hours")
+	if err != nil {
+		globalDailyHoursStr = "8.0" // 如果获取失败，提供一个安全的默认值
+	}
+	globalDailyHours, _ := strconv.ParseFloat(globalDailyHoursStr, 64)
+	return globalDailyHours
+}
+
+// resolveDailyCapacity 确定一个成员的“每日可用总工时”，个人配置优先于全局配置
+func resolveDailyCapacity(member model.User, globalDailyHours float64) float64 {
+	if member.DailyCapacityHours != nil && *member.DailyCapacityHours > 0 {
+		return *member.DailyCapacityHours
 	}
+	return globalDailyHours
+}
+
+// workCalendar 一段日期范围内的节假日和成员请假，用于在内存中判断某天是否为某个成员的可用工作日
+type workCalendar struct {
+	holidays map[string]bool
+	leaves   map[uuid.UUID]map[string]bool
+}
+
+// loadWorkCalendar 一次性加载 [start, end] 范围内的节假日和指定成员的请假
+func loadWorkCalendar(userIDs []uuid.UUID, start, end time.Time) (workCalendar, error) {
+	startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+
+	holidays, err := repository.GetHolidaysInRange(startDate, endDate)
+	if err != nil {
+		return workCalendar{}, err
+	}
+	leaves, err := repository.ListLeaveDatesForUsersInRange(userIDs, startDate, endDate)
+	if err != nil {
+		return workCalendar{}, err
+	}
+	return workCalendar{holidays: holidays, leaves: leaves}, nil
+}
+
+// availableFor 返回判断某天是否为该成员可用工作日的函数：排除周末、全局节假日和个人请假
+func (c workCalendar) availableFor(userID uuid.UUID) func(time.Time) bool {
+	leaves := c.leaves[userID]
+	return func(day time.Time) bool {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			return false
+		}
+		key := day.Format("2006-01-02")
+		return !c.holidays[key] && !leaves[key]
+	}
+}
+
+// latestDueDate 返回任务中最晚的截止日期，不早于 today
+func latestDueDate(tasks []model.Task, today time.Time) time.Time {
+	latest := today
+	for _, task := range tasks {
+		if task.DueDate != nil && task.DueDate.After(latest) {
+			latest = *task.DueDate
+		}
+	}
+	return latest
+}
+
+// calculateDailyLoad 计算单个成员的进行中任务对今天产生的负载，同时返回是否存在超期任务
+func calculateDailyLoad(userID uuid.UUID, tasks []model.Task, today time.Time) (float64, bool) {
+	calendar, err := loadWorkCalendar([]uuid.UUID{userID}, today, latestDueDate(tasks, today))
+	if err != nil {
+		log.Printf("Failed to load work calendar for user %s: %v", userID, err)
+	}
+	return sumDailyLoad(tasks, today, calendar.availableFor(userID))
+}
+
+// sumDailyLoad 循环计算每个进行中的任务对今天产生的负载，同时返回是否存在超期任务
+// 未超期的任务按今天到截止日期之间的可用工作日数分摊，即使今天本身不可用 (周末、节假日、请假) 也计入今天
+func sumDailyLoad(tasks []model.Task, today time.Time, isAvailable func(time.Time) bool) (float64, bool) {
+	var dailyLoad float64
+	var hasOverdueTask bool
+	startDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+
+	for _, task := range tasks {
+		// 如果任务没有截止日期，其全部工时都算作“技术债务”，压在今天
+		if task.DueDate == nil {
+			dailyLoad += float64(task.Effort)
+			continue
+		}
+
+		// 如果任务已超期，其全部剩余工时也都算作今天的负载
+		if isTaskOverdue(task, today) {
+			dailyLoad += float64(task.Effort)
+			hasOverdueTask = true
+			continue
+		}
+
+		// 对于未超期的任务，进行线性负载分配：先计算从今天到任务截止日期的“实际可用工作日”
+		dueDate := time.Date(task.DueDate.Year(), task.DueDate.Month(), task.DueDate.Day(), 0, 0, 0, 0, today.Location())
+		availableDays := 0
+		for d := startDate; !d.After(dueDate); d = d.AddDate(0, 0, 1) {
+			if isAvailable(d) {
+				availableDays++
+			}
+		}
+
+		if availableDays > 0 {
+			dailyLoad += float64(task.Effort) / float64(availableDays)
+		} else {
+			// 如果可用工作日为0（比如截止日期是今天，但今天是节假日或请假日），则全部工时压在今天
+			dailyLoad += float64(task.Effort)
+		}
+	}
+
+	return dailyLoad, hasOverdueTask
+}
+
+// isTaskOverdue 任务的截止时间已经过去 (人员看板、产能预测和指派推荐使用同一定义)
+func isTaskOverdue(task model.Task, now time.Time) bool {
+	return task.DueDate != nil && task.DueDate.Before(now)
+}
+
+// getUserLoadPercentage 计算单个用户今天的负载百分比 (与人员看板使用相同的算法)
+func getUserLoadPercentage(user model.User) (float64, error) {
+	tasks, err := repository.FindInProgressTasksForUser(user.ID)
+	if err != nil {
+		return 0, err
+	}
+	dailyLoad, _ := calculateDailyLoad(user.ID, tasks, time.Now())
+	dailyCapacity := resolveDailyCapacity(user, getGlobalDailyHours())
+	if dailyCapacity <= 0 {
+		return 0, nil
+	}
+	return (dailyLoad / dailyCapacity) * 100, nil
 }
 
 // GetUserPerformanceMetrics 是一个辅助函数，用于获取并计算单个用户的绩效分
@@ -166,12 +303,16 @@ func GetUserPerformanceMetrics(userID uuid.UUID) (*PerformanceMetricsDto, error)
 		return nil, err // 如果不是executor或找不到用户，则不计算
 	}
 
-	metrics, err := repository.GetPerformanceMetricsForUser(userID)
+	metrics, err := repository.GetPerformanceMetricsForUser(userID, defaultScoreBasis())
 	if err != nil {
 		return nil, err // 如果查询出错，不计算
 	}
 
-	// 只有当有数据时才计算（避免除以0）
+	return buildPerformanceMetricsDto(metrics), nil
+}
+
+// buildPerformanceMetricsDto 根据各项评价平均分计算综合分
+func buildPerformanceMetricsDto(metrics repository.PerformanceMetrics) *PerformanceMetricsDto {
 	// 此处可以加入更复杂的逻辑，比如完成任务数少于N个则不计算
 	compositeScore := (metrics.AvgTimeliness + metrics.AvgQuality + metrics.AvgCollaboration + metrics.AvgComplexity) / 4.0
 
@@ -181,5 +322,5 @@ func GetUserPerformanceMetrics(userID uuid.UUID) (*PerformanceMetricsDto, error)
 		AvgQuality:       metrics.AvgQuality,
 		AvgCollaboration: metrics.AvgCollaboration,
 		AvgComplexity:    metrics.AvgComplexity,
-	}, nil
+	}
 }
@@ -0,0 +1,375 @@
+// internal/service/recommendation_service.go
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// 各推荐因素的权重，合计为 1
+const (
+	recommendWeightCurrentLoad  = 0.25
+	recommendWeightForecastLoad = 0.20
+	recommendWeightAvailability = 0.20
+	recommendWeightTypeHistory  = 0.20
+	recommendWeightDifficulty   = 0.10
+	recommendWeightTeam         = 0.05
+
+	// 任务没有截止日期时，按接下来的这么多个工作日评估可用性和预测负载
+	recommendDefaultWindowDays = 5
+	defaultRecommendationLimit = 5
+)
+
+// RecommendationFactor 单个推荐因素的得分 (0~1) 及说明
+type RecommendationFactor struct {
+	Factor string  `json:"factor"`
+	Weight float64 `json:"weight"`
+	Score  float64 `json:"score"`
+	Detail string  `json:"detail"`
+}
+
+// AssigneeRecommendation 一位候选执行者的综合得分 (0~100)、各因素明细和风险提示
+type AssigneeRecommendation struct {
+	User     model.User             `json:"user"`
+	Score    float64                `json:"score"`
+	Factors  []RecommendationFactor `json:"factors"`
+	Warnings []string               `json:"warnings,omitempty"`
+
+	availableDays int  // 评估窗口内的可用工作日，自动指派时用于排除完全不可用的人
+	skillMismatch bool // 不满足任务的技能要求，自动指派时排除
+}
+
+// TaskRecommendations 一个任务池任务的候选执行者排名
+type TaskRecommendations struct {
+	TaskID          uint                     `json:"task_id"`
+	WindowStart     string                   `json:"window_start"`
+	WindowEnd       string                   `json:"window_end"`
+	Recommendations []AssigneeRecommendation `json:"recommendations"`
+}
+
+// GetAssigneeRecommendationsService 为任务池中的任务推荐执行者，按综合得分从高到低排序
+func GetAssigneeRecommendationsService(taskID uint, limit int) (TaskRecommendations, error) {
+	task, err := repository.FindTaskByID(taskID)
+	if err != nil {
+		return TaskRecommendations{}, errors.New("task not found")
+	}
+	if task.Status != "in_pool" {
+		return TaskRecommendations{}, errors.New("recommendations are only available for tasks in the task pool")
+	}
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+
+	result, err := rankAssignees(task)
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+	if len(result.Recommendations) > limit {
+		result.Recommendations = result.Recommendations[:limit]
+	}
+	return result, nil
+}
+
+// rankAssignees 计算所有执行者对该任务的推荐得分
+func rankAssignees(task model.Task) (TaskRecommendations, error) {
+	statuses, err := GetPersonnelStatusService("")
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+	var candidates []PersonnelStatus
+	var candidateIDs []uuid.UUID
+	for _, status := range statuses {
+		if status.User.Role == "executor" {
+			candidates = append(candidates, status)
+			candidateIDs = append(candidateIDs, status.User.ID)
+		}
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	windowEnd, err := recommendationWindowEnd(task, today)
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+	result := TaskRecommendations{
+		TaskID:          task.ID,
+		WindowStart:     today.Format("2006-01-02"),
+		WindowEnd:       windowEnd.Format("2006-01-02"),
+		Recommendations: []AssigneeRecommendation{},
+	}
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	// 一次性加载候选人的进行中任务、日历和历史数据
+	allTasks, err := repository.FindInProgressTasksForUsers(candidateIDs)
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+	tasksByUser := make(map[uuid.UUID][]model.Task)
+	for _, t := range allTasks {
+		if t.AssigneeID != nil {
+			tasksByUser[*t.AssigneeID] = append(tasksByUser[*t.AssigneeID], t)
+		}
+	}
+	calendarEnd := latestDueDate(allTasks, windowEnd)
+	calendar, err := loadWorkCalendar(candidateIDs, today, calendarEnd)
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+
+	typeHistory := map[uuid.UUID]repository.TaskTypePerformance{}
+	if task.TaskTypeID != nil {
+		if typeHistory, err = repository.GetTaskTypePerformanceForUsers(*task.TaskTypeID, candidateIDs); err != nil {
+			return TaskRecommendations{}, err
+		}
+	}
+	bestTypeScore := 0.0
+	for _, h := range typeHistory {
+		bestTypeScore = math.Max(bestTypeScore, h.AvgCompositeScore)
+	}
+	experience, err := repository.GetDifficultyExperienceForUsers(candidateIDs)
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+	taskDifficulty, hasDifficulty := taskCompositeDifficulty(task)
+	requirements, err := requiredSkillsForTask(task)
+	if err != nil {
+		return TaskRecommendations{}, err
+	}
+	skillLevels := map[uuid.UUID]map[uuid.UUID]int{}
+	if len(requirements.Requirements) > 0 {
+		if skillLevels, err = loadSkillLevelsForUsers(candidateIDs); err != nil {
+			return TaskRecommendations{}, err
+		}
+	}
+
+	creatorTeam := ""
+	if creator, err := repository.FindUserByID(task.CreatorID); err == nil {
+		creatorTeam = strings.TrimSpace(creator.Team)
+	}
+
+	globalDailyHours := getGlobalDailyHours()
+	for _, candidate := range candidates {
+		member := candidate.User
+		isAvailable := calendar.availableFor(member.ID)
+		rec := AssigneeRecommendation{User: member}
+
+		// 1. 当前负载：与人员看板一致
+		rec.addFactor("current_load", recommendWeightCurrentLoad, 1-candidate.LoadPercentage/100,
+			fmt.Sprintf("current load is %.0f%% of daily capacity (%s)", candidate.LoadPercentage, candidate.StatusLight))
+		if candidate.StatusLight == "overloaded" {
+			rec.Warnings = append(rec.Warnings, "currently overloaded")
+		}
+
+		// 2. 窗口内的可用工作日：排除周末、节假日和请假
+		workingDays, availableDays := 0, 0
+		for d := today; !d.After(windowEnd); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || calendar.holidays[d.Format("2006-01-02")] {
+				continue
+			}
+			workingDays++
+			if isAvailable(d) {
+				availableDays++
+			}
+		}
+		rec.availableDays = availableDays
+		availability := 0.0
+		if workingDays > 0 {
+			availability = float64(availableDays) / float64(workingDays)
+		}
+		rec.addFactor("availability", recommendWeightAvailability, availability,
+			fmt.Sprintf("available on %d of %d working days until %s", availableDays, workingDays, result.WindowEnd))
+		if availableDays == 0 {
+			rec.Warnings = append(rec.Warnings, "no available working days before the due date")
+		} else if !isAvailable(today) {
+			rec.Warnings = append(rec.Warnings, "not available today")
+		}
+
+		// 3. 预测负载：窗口内已有任务的分摊工时加上本任务工时，占窗口内总产能的比例
+		capacity := resolveDailyCapacity(member, globalDailyHours) * float64(availableDays)
+		windowLoad := 0.0
+		for dateKey, hours := range distributeTaskLoad(tasksByUser[member.ID], now, isAvailable) {
+			if date, err := time.ParseInLocation("2006-01-02", dateKey, today.Location()); err == nil && !date.After(windowEnd) {
+				windowLoad += hours
+			}
+		}
+		projected := 100.0
+		if capacity > 0 {
+			projected = (windowLoad + float64(task.Effort)) / capacity * 100
+		}
+		rec.addFactor("forecast_load", recommendWeightForecastLoad, 1-projected/100,
+			fmt.Sprintf("projected load until %s would be %.0f%% of capacity including this task (%dh)", result.WindowEnd, projected, task.Effort))
+		if projected > 100 && availableDays > 0 {
+			rec.Warnings = append(rec.Warnings, "projected load exceeds capacity before the due date")
+		}
+
+		// 4. 同类型任务的历史表现：相对于候选人中的最好成绩
+		if history, ok := typeHistory[member.ID]; ok && bestTypeScore > 0 {
+			rec.addFactor("task_type_history", recommendWeightTypeHistory, history.AvgCompositeScore/bestTypeScore,
+				fmt.Sprintf("completed %d tasks of this type with an average score of %.2f", history.CompletedCount, history.AvgCompositeScore))
+		} else if task.TaskTypeID == nil {
+			rec.addFactor("task_type_history", recommendWeightTypeHistory, 0.5, "task has no type, history not considered")
+		} else {
+			rec.addFactor("task_type_history", recommendWeightTypeHistory, 0.5, "no evaluated tasks of this type yet")
+		}
+
+		// 5. 难度匹配：任务难度不超过其做过的最高难度时视为完全匹配
+		exp, hasExp := experience[member.ID]
+		switch {
+		case !hasDifficulty:
+			rec.addFactor("difficulty_fit", recommendWeightDifficulty, 0.5, "task has no difficulty rating")
+		case !hasExp || exp.RatedCount == 0:
+			rec.addFactor("difficulty_fit", recommendWeightDifficulty, 0.5,
+				fmt.Sprintf("task difficulty is %.1f, no rated tasks completed yet", taskDifficulty))
+		case taskDifficulty <= exp.MaxDifficulty:
+			rec.addFactor("difficulty_fit", recommendWeightDifficulty, 1,
+				fmt.Sprintf("task difficulty %.1f is within the range handled before (max %.1f, average %.1f)", taskDifficulty, exp.MaxDifficulty, exp.AvgDifficulty))
+		default:
+			rec.addFactor("difficulty_fit", recommendWeightDifficulty, 1-(taskDifficulty-exp.MaxDifficulty)/taskDifficulty,
+				fmt.Sprintf("task difficulty %.1f is above the highest handled before (%.1f)", taskDifficulty, exp.MaxDifficulty))
+		}
+
+		// 6. 团队：与任务创建人同团队优先
+		memberTeam := strings.TrimSpace(member.Team)
+		switch {
+		case creatorTeam == "":
+			rec.addFactor("team", recommendWeightTeam, 0.5, "task creator has no team")
+		case strings.EqualFold(memberTeam, creatorTeam):
+			rec.addFactor("team", recommendWeightTeam, 1, "same team as the task creator ("+creatorTeam+")")
+		default:
+			rec.addFactor("team", recommendWeightTeam, 0, "different team from the task creator ("+creatorTeam+")")
+		}
+
+		// 技能要求不计入得分，不满足时给出提示，且自动指派时不会选择该候选人
+		if gaps := skillGaps(requirements.Requirements, skillLevels[member.ID]); len(gaps) > 0 {
+			rec.skillMismatch = true
+			rec.Warnings = append(rec.Warnings, gaps...)
+		}
+
+		rec.Score = math.Round(rec.Score*1000) / 10
+		result.Recommendations = append(result.Recommendations, rec)
+	}
+
+	sort.SliceStable(result.Recommendations, func(i, j int) bool {
+		return result.Recommendations[i].Score > result.Recommendations[j].Score
+	})
+	return result, nil
+}
+
+// addFactor 记录一个因素的得分 (截断到 0~1) 并累加到加权总分
+func (r *AssigneeRecommendation) addFactor(name string, weight, score float64, detail string) {
+	score = math.Max(0, math.Min(1, score))
+	r.Factors = append(r.Factors, RecommendationFactor{Factor: name, Weight: weight, Score: math.Round(score*100) / 100, Detail: detail})
+	r.Score += weight * score
+}
+
+// recommendationWindowEnd 评估窗口的结束日期：任务截止日期，没有截止日期时为接下来第 N 个工作日
+func recommendationWindowEnd(task model.Task, today time.Time) (time.Time, error) {
+	if task.DueDate != nil {
+		due := time.Date(task.DueDate.Year(), task.DueDate.Month(), task.DueDate.Day(), 0, 0, 0, 0, today.Location())
+		if due.Before(today) {
+			return today, nil
+		}
+		return due, nil
+	}
+	holidays, err := repository.GetHolidaysInRange(today, today.AddDate(0, 0, 60))
+	if err != nil {
+		return today, err
+	}
+	end, count := today, 0
+	for d := today; count < recommendDefaultWindowDays; d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday && !holidays[d.Format("2006-01-02")] {
+			count++
+			end = d
+		}
+	}
+	return end, nil
+}
+
+// taskCompositeDifficulty 读取任务审批时给出的技术难度综合分
+func taskCompositeDifficulty(task model.Task) (float64, bool) {
+	if len(task.DifficultyRating) == 0 {
+		return 0, false
+	}
+	var rating map[string]float64
+	if err := json.Unmarshal(task.DifficultyRating, &rating); err != nil {
+		return 0, false
+	}
+	score, ok := rating["composite_difficulty_score"]
+	return score, ok && score > 0
+}
+
+// --- 任务池自动指派 ---
+
+// JobTypeAutoAssignPoolTask 任务进入任务池后，按推荐结果自动指派给得分最高的执行者
+const JobTypeAutoAssignPoolTask = "auto_assign_pool_task"
+
+// AutoAssignPoolTaskPayload 自动指派作业的参数
+type AutoAssignPoolTaskPayload struct {
+	TaskID uint `json:"task_id"`
+}
+
+func init() {
+	RegisterJobHandler(JobTypeAutoAssignPoolTask, func(payload json.RawMessage) error {
+		var p AutoAssignPoolTaskPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return autoAssignPoolTask(p.TaskID)
+	})
+}
+
+// scheduleAutoAssign 在开启了任务池自动指派时，为刚进入任务池的任务排队一个自动指派作业
+func scheduleAutoAssign(taskID uint) {
+	if !getConfigBool("pool_auto_assign_enabled", false) {
+		return
+	}
+	if err := EnqueueJob(JobTypeAutoAssignPoolTask, AutoAssignPoolTaskPayload{TaskID: taskID}); err != nil {
+		log.Printf("Failed to enqueue auto-assign for task %d: %v", taskID, err)
+	}
+}
+
+// autoAssignPoolTask 将任务指派给得分最高、且得分不低于阈值、满足技能要求并在截止日期前有可用工作日的执行者
+// 任务已被领取或指派时直接结束；没有合适人选时任务留在任务池等待人工处理
+func autoAssignPoolTask(taskID uint) error {
+	task, err := repository.FindTaskByID(taskID)
+	if err != nil || task.Status != "in_pool" || task.AssigneeID != nil {
+		return nil
+	}
+
+	ranking, err := rankAssignees(task)
+	if err != nil {
+		return err
+	}
+	minScore := getConfigFloat("pool_auto_assign_min_score", 60)
+	for _, rec := range ranking.Recommendations {
+		if rec.Score < minScore {
+			break
+		}
+		if rec.availableDays == 0 || rec.skillMismatch {
+			continue
+		}
+		assigned, err := repository.AssignPoolTaskIfUnclaimed(task.ID, rec.User.ID, nil)
+		if err != nil {
+			return err
+		}
+		if assigned {
+			log.Printf("Auto-assigned task %d to %s (score %.1f).", task.ID, rec.User.Username, rec.Score)
+		}
+		return nil
+	}
+	log.Printf("No candidate reached the auto-assign score threshold for task %d, leaving it in the pool.", task.ID)
+	return nil
+}
@@ -0,0 +1,299 @@
+// internal/service/report_render_service.go
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotasksys/pkg/xlsx"
+)
+
+// reportContentTypes 支持的报告格式及其 Content-Type
+var reportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"html": "text/html; charset=utf-8",
+}
+
+// ReportContentType 返回报告格式对应的 Content-Type
+func ReportContentType(format string) string {
+	return reportContentTypes[format]
+}
+
+// RenderPerformanceReport 将报告渲染为指定格式，返回文件内容、Content-Type 和建议的文件名
+func RenderPerformanceReport(packet PerformanceReportPacket, format string) ([]byte, string, string, error) {
+	var content []byte
+	var err error
+	switch format {
+	case "csv":
+		content, err = renderReportCSV(packet)
+	case "xlsx":
+		content, err = renderReportXLSX(packet)
+	case "html":
+		content, err = renderReportHTML(packet)
+	default:
+		return nil, "", "", errors.New("invalid format, must be one of: csv, xlsx, html")
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+	return content, reportContentTypes[format], reportFileName(packet, format), nil
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// reportFileName 生成形如 'performance_team_ops_2026-Q3.xlsx' 的文件名
+func reportFileName(packet PerformanceReportPacket, format string) string {
+	parts := []string{"performance", packet.ScopeType}
+	switch packet.ScopeType {
+	case "user":
+		if len(packet.Members) == 1 {
+			parts = append(parts, packet.Members[0].User.Username)
+		}
+	case "team":
+		parts = append(parts, packet.ScopeValue)
+	}
+	parts = append(parts, packet.Period.Label)
+	name := unsafeFileNameChars.ReplaceAllString(strings.Join(parts, "_"), "-")
+	return name + "." + format
+}
+
+// reportSummaryHeader 汇总表的列，与 reportSummaryCells 的顺序一致
+var reportSummaryHeader = []interface{}{
+	"Member", "Username", "Team", "Completed Tasks", "Effort Delivered",
+	"Composite Score", "Timeliness", "Quality", "Collaboration", "Complexity", "Evaluated Tasks", "Raw Score Fallbacks",
+	"Rework Count", "Tasks With Due Date", "Overdue", "Overdue Rate",
+	"Transfers In", "Transfers Out", "Effort Handed Off",
+}
+
+func reportSummaryCells(name, username, team string, s ReportSummary) []interface{} {
+	return []interface{}{
+		name, username, team, s.CompletedTasks, s.EffortDelivered,
+		optionalScore(s.Evaluation.CompositeScore), optionalScore(s.Evaluation.AvgTimeliness),
+		optionalScore(s.Evaluation.AvgQuality), optionalScore(s.Evaluation.AvgCollaboration),
+		optionalScore(s.Evaluation.AvgComplexity), s.Evaluation.SampleSize, s.Evaluation.RawFallbackCount,
+		s.ReworkCount, s.TasksWithDueDate, s.OverdueCount, s.OverdueRate,
+		s.TransfersIn, s.TransfersOut, s.EffortHandedOff,
+	}
+}
+
+// reportTaskHeader 任务明细表的列，与 reportTaskCells 的顺序一致
+var reportTaskHeader = []interface{}{
+	"Member", "Task ID", "Task Key", "Title", "Task Type", "Effort", "Difficulty",
+	"Due Date", "Submitted At", "Completed At", "Overdue", "Transferred In",
+	"Composite Score", "Timeliness", "Quality", "Collaboration", "Complexity",
+}
+
+func reportTaskCells(member string, t ReportTaskLine) []interface{} {
+	return []interface{}{
+		member, int(t.TaskID), t.TaskKey, t.Title, t.TaskType, t.Effort, optionalScore(t.Difficulty),
+		formatReportTime(t.DueDate), formatReportTime(t.SubmittedAt), formatReportTime(t.CompletedAt),
+		yesNo(t.Overdue), yesNo(t.WasTransferredIn),
+		optionalScore(t.CompositeScore), optionalScore(t.Timeliness), optionalScore(t.Quality),
+		optionalScore(t.Collaboration), optionalScore(t.Complexity),
+	}
+}
+
+// renderReportCSV 渲染为 CSV：先是成员汇总，空一行后是任务明细
+func renderReportCSV(packet PerformanceReportPacket) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	write := func(cells []interface{}) {
+		record := make([]string, len(cells))
+		for i, cell := range cells {
+			record[i] = csvCell(cell)
+		}
+		_ = w.Write(record)
+	}
+
+	write([]interface{}{packet.Title})
+	write([]interface{}{"Period", formatReportDate(packet.Period.Start), formatReportDate(packet.Period.End.AddDate(0, 0, -1))})
+	write([]interface{}{"Score basis", packet.ScoreBasis})
+	write([]interface{}{})
+	write(reportSummaryHeader)
+	for _, m := range packet.Members {
+		write(reportSummaryCells(m.User.RealName, m.User.Username, m.User.Team, m.Summary))
+	}
+	if len(packet.Members) > 1 {
+		write(reportSummaryCells("Total", "", "", packet.Totals))
+	}
+	write([]interface{}{})
+	write(reportTaskHeader)
+	for _, m := range packet.Members {
+		for _, t := range m.Tasks {
+			write(reportTaskCells(m.User.RealName, t))
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// renderReportXLSX 渲染为 XLSX：Summary 和 Tasks 两个工作表
+func renderReportXLSX(packet PerformanceReportPacket) ([]byte, error) {
+	wb := xlsx.NewWorkbook()
+
+	summary := wb.AddSheet("Summary")
+	summary.AddHeader(packet.Title)
+	summary.AddRow("Period", formatReportDate(packet.Period.Start), formatReportDate(packet.Period.End.AddDate(0, 0, -1)))
+	summary.AddRow("Score basis", packet.ScoreBasis)
+	summary.AddRow()
+	summary.AddHeader(reportSummaryHeader...)
+	for _, m := range packet.Members {
+		summary.AddRow(reportSummaryCells(m.User.RealName, m.User.Username, m.User.Team, m.Summary)...)
+	}
+	if len(packet.Members) > 1 {
+		summary.AddHeader(reportSummaryCells("Total", "", "", packet.Totals)...)
+	}
+
+	tasks := wb.AddSheet("Tasks")
+	tasks.AddHeader(reportTaskHeader...)
+	for _, m := range packet.Members {
+		for _, t := range m.Tasks {
+			tasks.AddRow(reportTaskCells(m.User.RealName, t)...)
+		}
+	}
+	return wb.Bytes()
+}
+
+// renderReportHTML 渲染为可直接打印的 HTML 页面，每个成员一节，打印时分页
+func renderReportHTML(packet PerformanceReportPacket) ([]byte, error) {
+	var buf bytes.Buffer
+	err := reportHTMLTemplate.Execute(&buf, struct {
+		PerformanceReportPacket
+		LastDay        time.Time
+		SummaryHeader  []interface{}
+		TaskHeader     []interface{}
+		ShowTotals     bool
+		TotalsCells    []interface{}
+		MemberSections []reportHTMLMember
+	}{
+		PerformanceReportPacket: packet,
+		LastDay:                 packet.Period.End.AddDate(0, 0, -1),
+		SummaryHeader:           reportSummaryHeader,
+		TaskHeader:              reportTaskHeader[1:],
+		ShowTotals:              len(packet.Members) > 1,
+		TotalsCells:             reportSummaryCells("Total", "", "", packet.Totals),
+		MemberSections:          buildReportHTMLMembers(packet),
+	})
+	return buf.Bytes(), err
+}
+
+type reportHTMLMember struct {
+	Name         string
+	SummaryCells []interface{}
+	TaskRows     [][]interface{}
+}
+
+func buildReportHTMLMembers(packet PerformanceReportPacket) []reportHTMLMember {
+	sections := make([]reportHTMLMember, 0, len(packet.Members))
+	for _, m := range packet.Members {
+		section := reportHTMLMember{
+			Name:         m.User.RealName,
+			SummaryCells: reportSummaryCells(m.User.RealName, m.User.Username, m.User.Team, m.Summary),
+		}
+		for _, t := range m.Tasks {
+			section.TaskRows = append(section.TaskRows, reportTaskCells(m.User.RealName, t)[1:])
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+var reportHTMLTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"cell": cellText,
+	"date": formatReportDate,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; font-size: 12px; margin: 24px; color: #222; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+h2 { font-size: 16px; margin-top: 28px; border-bottom: 1px solid #ccc; padding-bottom: 4px; }
+.meta { color: #666; margin-bottom: 16px; }
+table { border-collapse: collapse; width: 100%; margin-top: 8px; }
+th, td { border: 1px solid #ccc; padding: 4px 6px; text-align: left; }
+th { background: #f3f3f3; }
+tr.total td { font-weight: bold; }
+.member { page-break-before: always; }
+@media print { body { margin: 0; } .member { break-before: page; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">Period: {{date .Period.Start}} – {{date .LastDay}} · Score basis: {{.ScoreBasis}} · Generated at {{.GeneratedAt.Format "2006-01-02 15:04"}}</div>
+<h2>Summary</h2>
+<table>
+<tr>{{range .SummaryHeader}}<th>{{.}}</th>{{end}}</tr>
+{{range .MemberSections}}<tr>{{range .SummaryCells}}<td>{{cell .}}</td>{{end}}</tr>
+{{end}}{{if .ShowTotals}}<tr class="total">{{range .TotalsCells}}<td>{{cell .}}</td>{{end}}</tr>
+{{end}}</table>
+{{$taskHeader := .TaskHeader}}{{range .MemberSections}}<div class="member">
+<h2>{{.Name}}</h2>
+{{if .TaskRows}}<table>
+<tr>{{range $taskHeader}}<th>{{.}}</th>{{end}}</tr>
+{{range .TaskRows}}<tr>{{range .}}<td>{{cell .}}</td>{{end}}</tr>
+{{end}}</table>{{else}}<p>No completed tasks in this period.</p>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
+
+// optionalScore 将可能缺失的分数转换为单元格值，缺失时为空单元格
+func optionalScore(score *float64) interface{} {
+	if score == nil {
+		return nil
+	}
+	return roundScore(*score)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func formatReportTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func formatReportDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// csvCell 将单元格值转换为 CSV 文本
+// 以 = + - @ (或制表符、回车) 开头的文本 (如任务标题) 会被电子表格当作公式执行，前面加单引号使其按文本显示；数值不受影响
+func csvCell(cell interface{}) string {
+	text := cellText(cell)
+	if _, isText := cell.(string); isText && text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
+
+// cellText 将单元格值转换为文本
+func cellText(cell interface{}) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return ""
+	}
+}
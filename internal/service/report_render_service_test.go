@@ -0,0 +1,34 @@
+// internal/service/report_render_service_test.go
+package service
+
+import "testing"
+
+func TestCSVCellEscapesFormulaPrefixes(t *testing.T) {
+	cases := []struct {
+		cell interface{}
+		want string
+	}{
+		{"=SUM(A1:A2)", "'=SUM(A1:A2)"},
+		{"+1", "'+1"},
+		{"-2", "'-2"},
+		{"@cmd", "'@cmd"},
+		{"\tTab", "'\tTab"},
+		{"\rReturn", "'\rReturn"},
+		{"a=b", "a=b"},
+		{"", ""},
+		{-5, "-5"},
+		{-1.5, "-1.5"},
+		{nil, ""},
+	}
+	for _, tc := range cases {
+		if got := csvCell(tc.cell); got != tc.want {
+			t.Errorf("csvCell(%#v) = %q, want %q", tc.cell, got, tc.want)
+		}
+	}
+}
+
+func TestCellTextIsNotEscaped(t *testing.T) {
+	if got := cellText("=SUM(A1:A2)"); got != "=SUM(A1:A2)" {
+		t.Errorf("cellText escaped a formula prefix: %q", got)
+	}
+}
@@ -0,0 +1,464 @@
+// internal/service/report_service.go
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// ReportPeriod 报告覆盖的时间段 [Start, End)
+type ReportPeriod struct {
+	Label string    `json:"label"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ReportTaskLine 报告中的一条已完成任务
+type ReportTaskLine struct {
+	TaskID           uint       `json:"task_id"`
+	TaskKey          string     `json:"task_key"`
+	Title            string     `json:"title"`
+	TaskType         string     `json:"task_type"`
+	Effort           int        `json:"effort"`
+	Difficulty       *float64   `json:"difficulty,omitempty"`
+	DueDate          *time.Time `json:"due_date,omitempty"`
+	SubmittedAt      *time.Time `json:"submitted_at,omitempty"`
+	CompletedAt      *time.Time `json:"completed_at,omitempty"`
+	Overdue          bool       `json:"overdue"`
+	Timeliness       *float64   `json:"timeliness,omitempty"`
+	Quality          *float64   `json:"quality,omitempty"`
+	Collaboration    *float64   `json:"collaboration,omitempty"`
+	Complexity       *float64   `json:"complexity,omitempty"`
+	CompositeScore   *float64   `json:"composite_score,omitempty"`
+	WasTransferredIn bool       `json:"was_transferred_in"`
+}
+
+// ReportSummary 一个成员 (或整个范围) 在报告周期内的汇总数据
+type ReportSummary struct {
+	CompletedTasks   int               `json:"completed_tasks"`
+	EffortDelivered  int               `json:"effort_delivered"`
+	Evaluation       PerformanceScores `json:"evaluation"`   // 按工时加权的评价明细
+	ReworkCount      int               `json:"rework_count"` // 本人创建的任务被审批驳回、需要返工的次数
+	TasksWithDueDate int               `json:"tasks_with_due_date"`
+	OverdueCount     int               `json:"overdue_count"`     // 提交 (或完成) 晚于截止日期的任务数
+	OverdueRate      float64           `json:"overdue_rate"`      // OverdueCount / TasksWithDueDate
+	TransfersIn      int               `json:"transfers_in"`      // 接手别人转交的任务数
+	TransfersOut     int               `json:"transfers_out"`     // 转交给别人的任务数
+	EffortHandedOff  int               `json:"effort_handed_off"` // 转出前已投入的工时
+}
+
+// MemberReport 单个成员的报告
+type MemberReport struct {
+	User    model.User       `json:"user"`
+	Summary ReportSummary    `json:"summary"`
+	Tasks   []ReportTaskLine `json:"tasks"`
+}
+
+// PerformanceReportPacket 一份完整的绩效报告 (评审材料)
+type PerformanceReportPacket struct {
+	Title       string         `json:"title"`
+	ScopeType   string         `json:"scope_type"` // 'user', 'team', 'all'
+	ScopeValue  string         `json:"scope_value"`
+	Period      ReportPeriod   `json:"period"`
+	ScoreBasis  string         `json:"score_basis"` // 'raw' 或 'normalized'，见 PerformanceQuery.ScoreBasis
+	GeneratedAt time.Time      `json:"generated_at"`
+	Totals      ReportSummary  `json:"totals"`
+	Members     []MemberReport `json:"members"`
+}
+
+// ReportRequest 生成报告的参数：UserID 和 Team 至少指定一个，都不指定时报告所有成员
+type ReportRequest struct {
+	UserID *uuid.UUID
+	Team   string
+	Period string // '2026-Q3', '2026-07', '2026'，为空时为上一个完整季度
+	Start  string // 与 End 一起指定自定义日期范围 (YYYY-MM-DD，包含首尾两天)
+	End    string
+
+	ScoreBasis string // 'raw' 或 'normalized'，为空时使用系统配置 performance_score_basis
+}
+
+// BuildPerformanceReportService 汇总一个用户或团队在指定周期内的绩效报告
+func BuildPerformanceReportService(req ReportRequest) (PerformanceReportPacket, error) {
+	period, err := resolveReportPeriod(req, time.Now())
+	if err != nil {
+		return PerformanceReportPacket{}, err
+	}
+
+	scoreBasis := req.ScoreBasis
+	switch scoreBasis {
+	case "":
+		scoreBasis = defaultScoreBasis()
+	case "raw", "normalized":
+	default:
+		return PerformanceReportPacket{}, errors.New("score_basis must be 'raw' or 'normalized'")
+	}
+
+	packet := PerformanceReportPacket{Period: period, ScoreBasis: scoreBasis, GeneratedAt: time.Now(), Members: []MemberReport{}}
+	var members []model.User
+	switch {
+	case req.UserID != nil:
+		user, err := repository.FindUserByID(*req.UserID)
+		if err != nil {
+			return PerformanceReportPacket{}, errors.New("user not found")
+		}
+		members = []model.User{user}
+		packet.ScopeType, packet.ScopeValue = "user", user.ID.String()
+		packet.Title = fmt.Sprintf("Performance report - %s - %s", user.RealName, period.Label)
+	default:
+		all, err := repository.FindAllActiveMembers()
+		if err != nil {
+			return PerformanceReportPacket{}, err
+		}
+		team := strings.TrimSpace(req.Team)
+		for _, member := range all {
+			if team == "" || strings.EqualFold(strings.TrimSpace(member.Team), team) {
+				members = append(members, member)
+			}
+		}
+		if team == "" {
+			packet.ScopeType = "all"
+			packet.Title = "Performance report - all members - " + period.Label
+		} else {
+			packet.ScopeType, packet.ScopeValue = "team", team
+			packet.Title = fmt.Sprintf("Performance report - team %s - %s", team, period.Label)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].RealName < members[j].RealName })
+
+	if err := fillReportMembers(&packet, members); err != nil {
+		return PerformanceReportPacket{}, err
+	}
+	return packet, nil
+}
+
+// fillReportMembers 一次性加载所有成员的任务、驳回和转交记录，并逐个成员汇总
+func fillReportMembers(packet *PerformanceReportPacket, members []model.User) error {
+	period := packet.Period
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.ID)
+	}
+
+	tasks, err := repository.ListCompletedTasksForUsersInRange(ids, period.Start, period.End)
+	if err != nil {
+		return err
+	}
+	rejections, err := repository.CountRejectionsForCreatorsInRange(ids, period.Start, period.End)
+	if err != nil {
+		return err
+	}
+	transfers, err := repository.ListAcceptedTransfersForUsersInRange(ids, period.Start, period.End)
+	if err != nil {
+		return err
+	}
+	taskTypes, err := repository.ListTaskTypes()
+	if err != nil {
+		return err
+	}
+	typeNames := make(map[uuid.UUID]string, len(taskTypes))
+	for _, tt := range taskTypes {
+		typeNames[tt.ID] = tt.Name
+	}
+
+	tasksByUser := make(map[uuid.UUID][]model.Task)
+	for _, task := range tasks {
+		if task.AssigneeID != nil {
+			tasksByUser[*task.AssigneeID] = append(tasksByUser[*task.AssigneeID], task)
+		}
+	}
+	transferredIn := make(map[string]bool) // "用户ID/任务ID" -> 该用户是否通过转交接手该任务
+	transfersIn := make(map[uuid.UUID]int)
+	transfersOut := make(map[uuid.UUID]int)
+	effortHandedOff := make(map[uuid.UUID]int)
+	for _, t := range transfers {
+		transfersIn[t.ToUserID]++
+		transfersOut[t.FromUserID]++
+		effortHandedOff[t.FromUserID] += t.EffortSpentByInitiator
+		transferredIn[t.ToUserID.String()+"/"+strconv.FormatUint(uint64(t.TaskID), 10)] = true
+	}
+
+	scoreQuery := PerformanceQuery{Weighting: "effort", MinSamples: 1, ScoreBasis: packet.ScoreBasis}
+	var allTasks []model.Task
+	for _, member := range members {
+		memberTasks := tasksByUser[member.ID]
+		allTasks = append(allTasks, memberTasks...)
+
+		report := MemberReport{User: member, Tasks: []ReportTaskLine{}}
+		for _, task := range memberTasks {
+			line := buildReportTaskLine(task, typeNames, packet.ScoreBasis)
+			line.WasTransferredIn = transferredIn[member.ID.String()+"/"+strconv.FormatUint(uint64(task.ID), 10)]
+			report.Tasks = append(report.Tasks, line)
+		}
+		report.Summary = summarizeReportTasks(memberTasks, scoreQuery)
+		report.Summary.ReworkCount = rejections[member.ID]
+		report.Summary.TransfersIn = transfersIn[member.ID]
+		report.Summary.TransfersOut = transfersOut[member.ID]
+		report.Summary.EffortHandedOff = effortHandedOff[member.ID]
+		packet.Members = append(packet.Members, report)
+	}
+
+	packet.Totals = summarizeReportTasks(allTasks, scoreQuery)
+	for _, m := range packet.Members {
+		packet.Totals.ReworkCount += m.Summary.ReworkCount
+		packet.Totals.TransfersIn += m.Summary.TransfersIn
+		packet.Totals.TransfersOut += m.Summary.TransfersOut
+		packet.Totals.EffortHandedOff += m.Summary.EffortHandedOff
+	}
+	return nil
+}
+
+// summarizeReportTasks 汇总一组已完成任务的数量、工时、评价和超期情况
+func summarizeReportTasks(tasks []model.Task, scoreQuery PerformanceQuery) ReportSummary {
+	summary := ReportSummary{CompletedTasks: len(tasks), Evaluation: scorePerformance(tasks, scoreQuery)}
+	for _, task := range tasks {
+		summary.EffortDelivered += task.Effort
+		if task.DueDate != nil {
+			summary.TasksWithDueDate++
+			if isTaskDeliveredLate(task) {
+				summary.OverdueCount++
+			}
+		}
+	}
+	if summary.TasksWithDueDate > 0 {
+		summary.OverdueRate = roundScore(float64(summary.OverdueCount) / float64(summary.TasksWithDueDate))
+	}
+	return summary
+}
+
+// isTaskDeliveredLate 负责人提交评价的时间 (没有记录时用完成时间) 晚于截止日期即视为超期
+func isTaskDeliveredLate(task model.Task) bool {
+	if task.DueDate == nil {
+		return false
+	}
+	delivered := task.SubmittedAt
+	if delivered == nil {
+		delivered = task.CompletedAt
+	}
+	return delivered != nil && delivered.After(*task.DueDate)
+}
+
+func buildReportTaskLine(task model.Task, typeNames map[uuid.UUID]string, scoreBasis string) ReportTaskLine {
+	line := ReportTaskLine{
+		TaskID:      task.ID,
+		Title:       task.Title,
+		Effort:      task.Effort,
+		DueDate:     task.DueDate,
+		SubmittedAt: task.SubmittedAt,
+		CompletedAt: task.CompletedAt,
+		Overdue:     isTaskDeliveredLate(task),
+	}
+	if task.TaskKey != nil {
+		line.TaskKey = *task.TaskKey
+	}
+	if task.TaskTypeID != nil {
+		line.TaskType = typeNames[*task.TaskTypeID]
+	}
+	if difficulty, ok := taskCompositeDifficulty(task); ok {
+		line.Difficulty = &difficulty
+	}
+	if scores, _, ok := taskScoresForBasis(task, scoreBasis); ok {
+		value := func(key string) *float64 { v := scores[key]; return &v }
+		line.Timeliness = value("timeliness")
+		line.Quality = value("quality")
+		line.Collaboration = value("collaboration")
+		line.Complexity = value("complexity")
+		line.CompositeScore = value("composite_score")
+	}
+	return line
+}
+
+// resolveReportPeriod 解析报告周期：自定义日期范围 > 周期标签 > 默认上一个完整季度
+func resolveReportPeriod(req ReportRequest, now time.Time) (ReportPeriod, error) {
+	loc := now.Location()
+	if req.Start != "" || req.End != "" {
+		start, err1 := time.ParseInLocation("2006-01-02", req.Start, loc)
+		end, err2 := time.ParseInLocation("2006-01-02", req.End, loc)
+		if err1 != nil || err2 != nil {
+			return ReportPeriod{}, errors.New("invalid date format for start or end. Use YYYY-MM-DD")
+		}
+		if end.Before(start) {
+			return ReportPeriod{}, errors.New("end date cannot be before start date")
+		}
+		return ReportPeriod{Label: req.Start + "_" + req.End, Start: start, End: end.AddDate(0, 0, 1)}, nil
+	}
+
+	label := strings.ToUpper(strings.TrimSpace(req.Period))
+	if label == "" {
+		currentQuarter := time.Date(now.Year(), time.Month((int(now.Month())-1)/3*3+1), 1, 0, 0, 0, 0, loc)
+		start := currentQuarter.AddDate(0, -3, 0)
+		return ReportPeriod{Label: quarterLabel(start), Start: start, End: currentQuarter}, nil
+	}
+	if year, quarter, ok := parseQuarterLabel(label); ok {
+		start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)
+		return ReportPeriod{Label: label, Start: start, End: start.AddDate(0, 3, 0)}, nil
+	}
+	if month, err := time.ParseInLocation("2006-01", label, loc); err == nil {
+		return ReportPeriod{Label: label, Start: month, End: month.AddDate(0, 1, 0)}, nil
+	}
+	if year, err := time.ParseInLocation("2006", label, loc); err == nil {
+		return ReportPeriod{Label: label, Start: year, End: year.AddDate(1, 0, 0)}, nil
+	}
+	return ReportPeriod{}, errors.New("invalid period, use e.g. '2026-Q3', '2026-07' or '2026'")
+}
+
+// parseQuarterLabel 解析 'YYYY-QN' 格式的季度标签
+func parseQuarterLabel(label string) (int, int, bool) {
+	parts := strings.Split(label, "-Q")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	year, err1 := strconv.Atoi(parts[0])
+	quarter, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || quarter < 1 || quarter > 4 || year < 1970 {
+		return 0, 0, false
+	}
+	return year, quarter, true
+}
+
+// --- 季度末自动生成 ---
+
+// JobTypeGenerateQuarterlyReports 季度结束后为每位经理生成上一季度的团队报告
+const JobTypeGenerateQuarterlyReports = "generate_quarterly_reports"
+
+// GenerateQuarterlyReportsPayload 季度报告作业的参数
+type GenerateQuarterlyReportsPayload struct {
+	Period string `json:"period"` // 如 '2026-Q3'
+}
+
+// quarterlyReportFormats 自动生成的报告格式
+var quarterlyReportFormats = []string{"xlsx", "html"}
+
+func init() {
+	RegisterJobHandler(JobTypeGenerateQuarterlyReports, func(payload json.RawMessage) error {
+		var p GenerateQuarterlyReportsPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+		return generateQuarterlyReports(p.Period)
+	})
+}
+
+// enqueueQuarterlyReports 由调度器在每个季度第一天触发，将上一季度的报告生成放入作业队列
+func enqueueQuarterlyReports() {
+	period, _ := resolveReportPeriod(ReportRequest{}, time.Now())
+	if err := EnqueueJob(JobTypeGenerateQuarterlyReports, GenerateQuarterlyReportsPayload{Period: period.Label}); err != nil {
+		log.Printf("Failed to enqueue quarterly reports for %s: %v", period.Label, err)
+	}
+}
+
+// catchUpQuarterlyReports 在成为 leader 时检查上一季度的报告，
+// 季度第一天的触发被错过 (例如当时没有实例在运行) 导致有经理缺少报告时补充生成
+func catchUpQuarterlyReports() {
+	period, _ := resolveReportPeriod(ReportRequest{}, time.Now())
+	managers, err := repository.FindUsersByRole("manager")
+	if err != nil {
+		log.Printf("Failed to check quarterly reports for %s: %v", period.Label, err)
+		return
+	}
+	for _, manager := range managers {
+		missing, err := missingQuarterlyReportFormats(manager.ID, period.Label)
+		if err != nil {
+			log.Printf("Failed to check quarterly reports for %s: %v", period.Label, err)
+			return
+		}
+		if len(missing) > 0 {
+			log.Printf("Quarterly reports for %s are missing, catching up.", period.Label)
+			enqueueQuarterlyReports()
+			return
+		}
+	}
+}
+
+// missingQuarterlyReportFormats 一位经理在某个周期还没有生成的季度报告格式
+func missingQuarterlyReportFormats(managerID uuid.UUID, period string) ([]string, error) {
+	existing, err := repository.ListGeneratedReportFormats(managerID, period)
+	if err != nil {
+		return nil, err
+	}
+	generated := make(map[string]bool, len(existing))
+	for _, format := range existing {
+		generated[format] = true
+	}
+	var missing []string
+	for _, format := range quarterlyReportFormats {
+		if !generated[format] {
+			missing = append(missing, format)
+		}
+	}
+	return missing, nil
+}
+
+// generateQuarterlyReports 为每位经理生成其所在团队 (未设置团队时为所有成员) 的季度报告
+// 已经生成过的报告不会重新生成，作业重试或重复入队时只补充缺少的报告
+func generateQuarterlyReports(period string) error {
+	managers, err := repository.FindUsersByRole("manager")
+	if err != nil {
+		return err
+	}
+	generated := 0
+	for _, manager := range managers {
+		formats, err := missingQuarterlyReportFormats(manager.ID, period)
+		if err != nil {
+			return err
+		}
+		if len(formats) == 0 {
+			continue
+		}
+		packet, err := BuildPerformanceReportService(ReportRequest{Team: manager.Team, Period: period})
+		if err != nil {
+			return err
+		}
+		for _, format := range formats {
+			content, _, fileName, err := RenderPerformanceReport(packet, format)
+			if err != nil {
+				return err
+			}
+			report := model.GeneratedReport{
+				OwnerID:     manager.ID,
+				ScopeType:   packet.ScopeType,
+				ScopeValue:  packet.ScopeValue,
+				PeriodLabel: packet.Period.Label,
+				PeriodStart: packet.Period.Start,
+				PeriodEnd:   packet.Period.End,
+				Format:      format,
+				FileName:    fileName,
+				Content:     content,
+				CreatedAt:   time.Now(),
+			}
+			if err := repository.SaveGeneratedReport(&report); err != nil {
+				return err
+			}
+		}
+		generated++
+	}
+	log.Printf("Generated %s performance reports for %d managers.", period, generated)
+	return nil
+}
+
+// ListGeneratedReportsService 获取当前经理的已生成报告
+func ListGeneratedReportsService(ownerID uuid.UUID) ([]model.GeneratedReport, error) {
+	return repository.ListGeneratedReports(ownerID)
+}
+
+// GetGeneratedReportService 下载一份已生成的报告，只有报告所属经理和系统管理员可以下载
+func GetGeneratedReportService(id uuid.UUID, userID uuid.UUID, userRole string) (model.GeneratedReport, error) {
+	report, err := repository.FindGeneratedReportByID(id)
+	if err != nil {
+		return model.GeneratedReport{}, errors.New("report not found")
+	}
+	if report.OwnerID != userID && userRole != "system_admin" {
+		return model.GeneratedReport{}, errors.New("permission denied")
+	}
+	return report, nil
+}
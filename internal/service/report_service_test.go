@@ -0,0 +1,59 @@
+// internal/service/report_service_test.go
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveReportPeriod(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, loc)
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+
+	cases := []struct {
+		name  string
+		req   ReportRequest
+		label string
+		start time.Time
+		end   time.Time // 不包含
+	}{
+		{"季度", ReportRequest{Period: "2026-Q3"}, "2026-Q3", date(2026, 7, 1), date(2026, 10, 1)},
+		{"小写季度", ReportRequest{Period: " 2026-q4 "}, "2026-Q4", date(2026, 10, 1), date(2027, 1, 1)},
+		{"月份", ReportRequest{Period: "2026-07"}, "2026-07", date(2026, 7, 1), date(2026, 8, 1)},
+		{"年份", ReportRequest{Period: "2026"}, "2026", date(2026, 1, 1), date(2027, 1, 1)},
+		{"自定义范围包含结束日", ReportRequest{Start: "2026-03-05", End: "2026-03-20"}, "2026-03-05_2026-03-20", date(2026, 3, 5), date(2026, 3, 21)},
+		{"单日范围", ReportRequest{Start: "2026-03-05", End: "2026-03-05"}, "2026-03-05_2026-03-05", date(2026, 3, 5), date(2026, 3, 6)},
+		{"默认上一个完整季度", ReportRequest{}, "2026-Q3", date(2026, 7, 1), date(2026, 10, 1)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			period, err := resolveReportPeriod(tc.req, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if period.Label != tc.label || !period.Start.Equal(tc.start) || !period.End.Equal(tc.end) {
+				t.Fatalf("got %s [%v, %v), want %s [%v, %v)", period.Label, period.Start, period.End, tc.label, tc.start, tc.end)
+			}
+		})
+	}
+}
+
+func TestResolveReportPeriodRejectsInvalidInput(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	cases := []ReportRequest{
+		{Period: "2026-Q5"},
+		{Period: "2026-13"},
+		{Period: "last-quarter"},
+		{Start: "2026-03-20", End: "2026-03-05"},
+		{Start: "2026-03-05"},
+		{Start: "2026/03/05", End: "2026/03/20"},
+	}
+	for _, req := range cases {
+		if _, err := resolveReportPeriod(req, now); err == nil {
+			t.Errorf("resolveReportPeriod(%+v) succeeded, want error", req)
+		}
+	}
+}
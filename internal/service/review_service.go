@@ -0,0 +1,317 @@
+// internal/service/review_service.go
+package service
+
+import (
+	"errors"
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// --- 数据结构定义 (DTOs) ---
+
+// ReviewQueueItem 审核队列中的一项：等待审批或等待评价的任务，以及其SLA状态
+type ReviewQueueItem struct {
+	Task              model.Task `json:"task"`
+	Stage             string     `json:"stage"` // 'approval', 'evaluation'
+	WaitingSince      time.Time  `json:"waiting_since"`
+	AgeHours          float64    `json:"age_hours"`
+	SLATargetHours    float64    `json:"sla_target_hours"`
+	SLARemainingHours float64    `json:"sla_remaining_hours"` // 为负数表示已超出SLA的时长
+	SLABreached       bool       `json:"sla_breached"`
+}
+
+// ReviewerStats 单个审核人的审批/评价时效统计
+type ReviewerStats struct {
+	Reviewer              model.User `json:"reviewer"`
+	ApprovalsCount        int        `json:"approvals_count"`
+	AvgHoursToApprove     float64    `json:"avg_hours_to_approve"`
+	MedianHoursToApprove  float64    `json:"median_hours_to_approve"`
+	ApprovalSLABreaches   int        `json:"approval_sla_breaches"`
+	EvaluationsCount      int        `json:"evaluations_count"`
+	AvgHoursToEvaluate    float64    `json:"avg_hours_to_evaluate"`
+	MedianHoursToEvaluate float64    `json:"median_hours_to_evaluate"`
+	EvaluationSLABreaches int        `json:"evaluation_sla_breaches"`
+	approvalDurations     []float64
+	evaluationDurations   []float64
+}
+
+// --- 核心服务函数 ---
+
+// GetReviewQueueService 获取经理的审核队列，按等待时长从长到短排序
+// stage 可选 'approval' 或 'evaluation'，为空时返回全部
+func GetReviewQueueService(stage string) ([]ReviewQueueItem, error) {
+	if stage != "" && stage != "approval" && stage != "evaluation" {
+		return nil, errors.New("invalid stage, must be 'approval' or 'evaluation'")
+	}
+
+	slaSettings, err := loadReviewSLASettings()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var items []ReviewQueueItem
+
+	// 1. 等待审批的任务，从创建时间开始计算等待时长
+	if stage == "" || stage == "approval" {
+		tasks, err := repository.ListTasksAwaitingApproval()
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			items = append(items, buildReviewQueueItem(task, "approval", task.CreatedAt, slaSettings, now))
+		}
+	}
+
+	// 2. 等待评价的任务，从提交评价的时间开始计算 (历史数据没有提交时间，退化为最后更新时间)
+	if stage == "" || stage == "evaluation" {
+		tasks, err := repository.ListMainTasksAwaitingEvaluation()
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			waitingSince := task.UpdatedAt
+			if task.SubmittedAt != nil {
+				waitingSince = *task.SubmittedAt
+			}
+			items = append(items, buildReviewQueueItem(task, "evaluation", waitingSince, slaSettings, now))
+		}
+	}
+
+	// 3. 等待最久的排在最前面
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].AgeHours > items[j].AgeHours
+	})
+
+	return items, nil
+}
+
+// GetReviewerStatsService 统计最近 days 天内每个审核人的审批、评价时效
+// 审批时长 = ApprovedAt - CreatedAt；评价时长 = CompletedAt - SubmittedAt
+func GetReviewerStatsService(days int) ([]ReviewerStats, error) {
+	if days <= 0 {
+		days = 90
+	}
+	since := time.Now().AddDate(0, 0, -days)
+
+	slaSettings, err := loadReviewSLASettings()
+	if err != nil {
+		return nil, err
+	}
+	approvedTasks, err := repository.ListTasksApprovedSince(since)
+	if err != nil {
+		return nil, err
+	}
+	evaluatedTasks, err := repository.ListTasksEvaluatedSince(since)
+	if err != nil {
+		return nil, err
+	}
+
+	statsByReviewer := make(map[uuid.UUID]*ReviewerStats)
+	getStats := func(id uuid.UUID) *ReviewerStats {
+		if stats, ok := statsByReviewer[id]; ok {
+			return stats
+		}
+		stats := &ReviewerStats{}
+		statsByReviewer[id] = stats
+		return stats
+	}
+
+	for _, task := range approvedTasks {
+		stats := getStats(*task.ReviewerID)
+		hours := task.ApprovedAt.Sub(task.CreatedAt).Hours()
+		stats.approvalDurations = append(stats.approvalDurations, hours)
+		if hours > slaSettings.target("approval", task) {
+			stats.ApprovalSLABreaches++
+		}
+	}
+	for _, task := range evaluatedTasks {
+		stats := getStats(*task.EvaluatorID)
+		hours := task.CompletedAt.Sub(*task.SubmittedAt).Hours()
+		stats.evaluationDurations = append(stats.evaluationDurations, hours)
+		if hours > slaSettings.target("evaluation", task) {
+			stats.EvaluationSLABreaches++
+		}
+	}
+
+	// 批量获取审核人信息
+	reviewerIDs := make([]uuid.UUID, 0, len(statsByReviewer))
+	for id := range statsByReviewer {
+		reviewerIDs = append(reviewerIDs, id)
+	}
+	reviewers, err := repository.FindUsersByIDs(reviewerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReviewerStats, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		stats := statsByReviewer[reviewer.ID]
+		stats.Reviewer = reviewer
+		stats.ApprovalsCount = len(stats.approvalDurations)
+		stats.AvgHoursToApprove = average(stats.approvalDurations)
+		stats.MedianHoursToApprove = percentile(stats.approvalDurations, 50)
+		stats.EvaluationsCount = len(stats.evaluationDurations)
+		stats.AvgHoursToEvaluate = average(stats.evaluationDurations)
+		stats.MedianHoursToEvaluate = percentile(stats.evaluationDurations, 50)
+		result = append(result, *stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Reviewer.RealName < result[j].Reviewer.RealName
+	})
+	return result, nil
+}
+
+// --- 审核SLA策略管理 ---
+
+func ListReviewSLAPoliciesService() ([]model.ReviewSLAPolicy, error) {
+	return repository.ListReviewSLAPolicies()
+}
+
+func CreateReviewSLAPolicyService(input model.ReviewSLAPolicy) (model.ReviewSLAPolicy, error) {
+	if err := validateReviewSLAPolicy(input); err != nil {
+		return model.ReviewSLAPolicy{}, err
+	}
+	err := repository.CreateReviewSLAPolicy(&input)
+	return input, err
+}
+
+func UpdateReviewSLAPolicyService(id uuid.UUID, input model.ReviewSLAPolicy) (model.ReviewSLAPolicy, error) {
+	if err := validateReviewSLAPolicy(input); err != nil {
+		return model.ReviewSLAPolicy{}, err
+	}
+	policy, err := repository.FindReviewSLAPolicyByID(id)
+	if err != nil {
+		return model.ReviewSLAPolicy{}, errors.New("review SLA policy not found")
+	}
+	policy.Stage = input.Stage
+	policy.Priority = input.Priority
+	policy.TaskTypeID = input.TaskTypeID
+	policy.TargetHours = input.TargetHours
+
+	err = repository.UpdateReviewSLAPolicy(&policy)
+	return policy, err
+}
+
+func DeleteReviewSLAPolicyService(id uuid.UUID) error {
+	return repository.DeleteReviewSLAPolicy(id)
+}
+
+// --- 辅助函数 ---
+
+// buildReviewQueueItem 计算一个待审核任务的等待时长与SLA状态
+func buildReviewQueueItem(task model.Task, stage string, waitingSince time.Time, slaSettings reviewSLASettings, now time.Time) ReviewQueueItem {
+	ageHours := now.Sub(waitingSince).Hours()
+	target := slaSettings.target(stage, task)
+	return ReviewQueueItem{
+		Task:              task,
+		Stage:             stage,
+		WaitingSince:      waitingSince,
+		AgeHours:          ageHours,
+		SLATargetHours:    target,
+		SLARemainingHours: target - ageHours,
+		SLABreached:       ageHours > target,
+	}
+}
+
+// reviewSLASettings 审核SLA策略及系统配置中的默认目标时长，在循环前一次性加载
+type reviewSLASettings struct {
+	policies               []model.ReviewSLAPolicy
+	defaultApprovalHours   float64
+	defaultEvaluationHours float64
+}
+
+// loadReviewSLASettings 一次性加载所有审核SLA策略和默认目标时长
+func loadReviewSLASettings() (reviewSLASettings, error) {
+	policies, err := repository.ListReviewSLAPolicies()
+	if err != nil {
+		return reviewSLASettings{}, err
+	}
+	return reviewSLASettings{
+		policies:               policies,
+		defaultApprovalHours:   getConfigFloat("review_sla_default_approval_hours", 24),
+		defaultEvaluationHours: getConfigFloat("review_sla_default_evaluation_hours", 48),
+	}, nil
+}
+
+// target 为任务匹配最具体的SLA策略：任务类型+优先级 > 任务类型 > 优先级 > 通配
+// 未匹配到任何策略时，使用系统配置中的默认值
+func (s reviewSLASettings) target(stage string, task model.Task) float64 {
+	bestScore := -1
+	var target float64
+	for _, policy := range s.policies {
+		if policy.Stage != stage {
+			continue
+		}
+		score := 0
+		if policy.TaskTypeID != nil {
+			if task.TaskTypeID == nil || *policy.TaskTypeID != *task.TaskTypeID {
+				continue
+			}
+			score += 2
+		}
+		if policy.Priority != nil {
+			if *policy.Priority != task.Priority {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			bestScore = score
+			target = policy.TargetHours
+		}
+	}
+	if bestScore >= 0 {
+		return target
+	}
+
+	if stage == "approval" {
+		return s.defaultApprovalHours
+	}
+	return s.defaultEvaluationHours
+}
+
+// validateReviewSLAPolicy 校验审核SLA策略的输入
+func validateReviewSLAPolicy(policy model.ReviewSLAPolicy) error {
+	if policy.Stage != "approval" && policy.Stage != "evaluation" {
+		return errors.New("invalid stage, must be 'approval' or 'evaluation'")
+	}
+	if policy.TargetHours <= 0 {
+		return errors.New("target hours must be greater than zero")
+	}
+	return nil
+}
+
+// average 计算一组数值的平均值，空集合返回0
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// percentile 使用线性插值计算一组数值的百分位数 (p 取值 0-100)，空集合返回0
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	fraction := rank - float64(lower)
+	return sorted[lower] + fraction*(sorted[lower+1]-sorted[lower])
+}
@@ -2,89 +2,502 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"gotasksys/internal/model"
 	"gotasksys/internal/repository"
+	"gotasksys/pkg/rrule"
 	"log"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
 )
 
 // 将调度器实例和它的任务ID映射设为全局，以便其他服务可以访问
+// 只有当前实例是 leader 时 cronScheduler 才不为 nil
 var (
 	cronScheduler *cron.Cron
 	jobIDs        map[string]cron.EntryID
+	jobVersions   map[string]time.Time // 已注册作业对应规则的 UpdatedAt，用于发现其他实例对规则的修改
+	schedulerMu   sync.Mutex
+	instanceID    string
 )
 
-// InitScheduler 初始化并启动所有定时任务
+// cronParser 是调度器实际使用的Cron解析器
+// 秒级字段可选：既支持标准的5段表达式，也支持带秒的6段表达式 (以支持更灵活的测试)
+// 规则的校验、预览都必须使用同一个解析器，保证"预览看到的"就是"实际会触发的"
+var cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+const (
+	// maxCatchUpRunsPerRule 单个规则在一次补偿中最多补跑的触发次数，防止高频规则在长时间停机后刷屏
+	maxCatchUpRunsPerRule = 100
+
+	// 多实例部署时的 leader 选举参数：leader 每隔 schedulerLeaseRenewInterval 续约一次，
+	// 租约在 schedulerLeaseTTL 内未续约即视为 leader 已宕机，其他实例可以接管
+	schedulerLeaseName          = "periodic_task_scheduler"
+	schedulerLeaseTTL           = 30 * time.Second
+	schedulerLeaseRenewInterval = 10 * time.Second
+)
+
+// ruleLocation 返回规则所在的时区，未设置时使用服务器本地时区
+func ruleLocation(pt model.PeriodicTask) *time.Location {
+	tz := pt.TimeZone
+	if tz == "" {
+		// 兼容直接写在Cron表达式中的 "CRON_TZ=Asia/Shanghai ..." 前缀
+		tz = cronTimeZonePrefix(pt.CronExpression)
+	}
+	if tz == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// cronTimeZonePrefix 提取Cron表达式中 "CRON_TZ=" / "TZ=" 前缀指定的时区
+func cronTimeZonePrefix(expr string) string {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(expr, prefix) {
+			if fields := strings.Fields(expr[len(prefix):]); len(fields) > 0 {
+				return fields[0]
+			}
+		}
+	}
+	return ""
+}
+
+// parseRuleSchedule 将规则解析为调度器可用的 Schedule，支持Cron表达式和 RFC 5545 RRULE 两种格式
+// 调度、补偿、预览和校验都通过这里解析，保证行为一致
+func parseRuleSchedule(pt model.PeriodicTask) (cron.Schedule, error) {
+	if pt.TimeZone != "" {
+		if _, err := time.LoadLocation(pt.TimeZone); err != nil {
+			return nil, errors.New("invalid time zone")
+		}
+	}
+	loc := ruleLocation(pt)
+
+	if pt.RRule != "" {
+		// RRULE 没有 DTSTART 时，以规则开始日期 (或创建日期) 的零点为起点
+		anchor := time.Now()
+		if pt.StartDate != nil {
+			anchor = *pt.StartDate
+		} else if !pt.CreatedAt.IsZero() {
+			anchor = pt.CreatedAt
+		}
+		anchor = anchor.In(loc)
+		dtstart := time.Date(anchor.Year(), anchor.Month(), anchor.Day(), 0, 0, 0, 0, loc)
+
+		rule, err := rrule.Parse(pt.RRule, dtstart, loc)
+		if err != nil {
+			return nil, errors.New("invalid recurrence rule: " + err.Error())
+		}
+		return rule, nil
+	}
+
+	expr := pt.CronExpression
+	if pt.TimeZone != "" && cronTimeZonePrefix(expr) == "" {
+		expr = "CRON_TZ=" + pt.TimeZone + " " + expr
+	}
+	schedule, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, errors.New("invalid cron expression format")
+	}
+	return schedule, nil
+}
+
+// validateRuleSchedule 校验规则必须且只能使用Cron表达式或 RRULE 其中一种，且能被正确解析
+func validateRuleSchedule(pt model.PeriodicTask) error {
+	if (pt.CronExpression == "") == (pt.RRule == "") {
+		return errors.New("exactly one of cron expression or recurrence rule is required")
+	}
+	_, err := parseRuleSchedule(pt)
+	return err
+}
+
+// ruleScheduleText 返回规则的调度文本，用于日志
+func ruleScheduleText(pt model.PeriodicTask) string {
+	text := pt.CronExpression
+	if pt.RRule != "" {
+		text = pt.RRule
+	}
+	if pt.TimeZone != "" {
+		text += " (" + pt.TimeZone + ")"
+	}
+	return text
+}
+
+// InitScheduler 初始化调度器，并参与 leader 选举
+// 多个实例同时运行时，只有持有数据库租约的实例会执行计划任务和系统级定时作业
 func InitScheduler() {
 	log.Println("Initializing scheduler...")
+	hostname, _ := os.Hostname()
+	instanceID = fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8])
+
+	// 启动时立即尝试一次，之后定期续约/竞选
+	runLeaderElection()
+	go func() {
+		ticker := time.NewTicker(schedulerLeaseRenewInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			runLeaderElection()
+		}
+	}()
+	log.Printf("Scheduler started (instance: %s).", instanceID)
+}
+
+// runLeaderElection 尝试获取或续约调度器租约，并根据结果成为 leader、同步作业或让出 leader 身份
+func runLeaderElection() {
+	acquired, err := repository.TryAcquireSchedulerLease(schedulerLeaseName, instanceID, schedulerLeaseTTL)
+	if err != nil {
+		// 无法确认租约时，为避免重复执行，主动让出 leader 身份
+		log.Printf("Error renewing scheduler lease: %v", err)
+		acquired = false
+	}
+
+	schedulerMu.Lock()
+	isLeader := cronScheduler != nil
+	schedulerMu.Unlock()
+
+	switch {
+	case acquired && !isLeader:
+		becomeLeader()
+	case acquired && isLeader:
+		syncJobs()
+	case !acquired && isLeader:
+		stepDown()
+	}
+}
+
+// becomeLeader 当前实例成为 leader：注册系统作业、补偿错过的触发并加载所有启用的计划任务
+func becomeLeader() {
+	log.Printf("Instance %s became scheduler leader.", instanceID)
+	scheduler := cron.New(cron.WithParser(cronParser))
+
+	// 注册系统级定时作业 (这些作业不是幂等的，执行前都会确认本实例仍持有租约)
+	// 每10分钟清理一次过期未处理的转交请求
+	if _, err := scheduler.AddFunc("0 */10 * * * *", leaderOnly(ExpireStaleTransfersService)); err != nil {
+		log.Printf("Error scheduling transfer expiry job: %v", err)
+	}
+	// 每分钟处理一次因节假日顺延/提前的计划任务
+	if _, err := scheduler.AddFunc("0 * * * * *", leaderOnly(processShiftedPeriodicRuns)); err != nil {
+		log.Printf("Error scheduling holiday shift job: %v", err)
+	}
+	// 每个季度第一天凌晨为经理生成上一季度的绩效报告
+	if _, err := scheduler.AddFunc("0 0 1 1 1,4,7,10 *", leaderOnly(enqueueQuarterlyReports)); err != nil {
+		log.Printf("Error scheduling quarterly report job: %v", err)
+	}
+
+	schedulerMu.Lock()
+	cronScheduler = scheduler
 	jobIDs = make(map[string]cron.EntryID)
-	// 使用带秒级的解析器，以支持更灵活的测试
-	cronScheduler = cron.New(cron.WithSeconds())
+	jobVersions = make(map[string]time.Time)
+	schedulerMu.Unlock()
 
 	periodicTasks, err := repository.ListAllActivePeriodicTasks()
 	if err != nil {
 		log.Printf("Error fetching periodic tasks on init: %v", err)
+	}
+	for _, pt := range periodicTasks {
+		AddJob(pt)
+	}
+	// 调度器启动之后的触发由定时作业负责，补偿只覆盖启动时刻及之前错过的触发
+	startedAt := time.Now()
+	scheduler.Start()
+
+	// 补偿停机 (或 leader 切换) 期间错过的触发可能耗时较长，放到后台执行，不阻塞租约续约
+	go catchUpAllMissedRuns(periodicTasks, startedAt)
+	go catchUpQuarterlyReports()
+}
+
+// catchUpAllMissedRuns 依次补偿每个规则在 until 之前错过的触发，失去 leader 身份后立即停止
+func catchUpAllMissedRuns(periodicTasks []model.PeriodicTask, until time.Time) {
+	for _, pt := range periodicTasks {
+		if !holdsSchedulerLease() {
+			log.Printf("Instance %s is no longer scheduler leader, stopping catch-up.", instanceID)
+			return
+		}
+		catchUpMissedRuns(pt, until)
+	}
+}
+
+// holdsSchedulerLease 确认本实例当前仍是 leader 且租约未过期
+func holdsSchedulerLease() bool {
+	schedulerMu.Lock()
+	isLeader := cronScheduler != nil
+	schedulerMu.Unlock()
+	if !isLeader {
+		return false
+	}
+	held, err := repository.IsSchedulerLeaseHeld(schedulerLeaseName, instanceID)
+	if err != nil {
+		log.Printf("Error checking scheduler lease: %v", err)
+		return false
+	}
+	return held
+}
+
+// leaderOnly 包装系统级定时作业：执行前确认本实例仍持有租约，避免租约被其他实例接管后重复执行
+func leaderOnly(job func()) func() {
+	return func() {
+		if !holdsSchedulerLease() {
+			log.Printf("Instance %s skipped a system job: scheduler lease is not held.", instanceID)
+			return
+		}
+		job()
+	}
+}
+
+// stepDown 当前实例失去租约：停止调度器，不再执行任何计划任务
+func stepDown() {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+	if cronScheduler == nil {
 		return
 	}
+	cronScheduler.Stop()
+	cronScheduler = nil
+	jobIDs = nil
+	jobVersions = nil
+	log.Printf("Instance %s lost scheduler leadership.", instanceID)
+}
 
+// syncJobs 将 leader 上注册的作业与数据库中的规则对齐
+// 其他实例通过 CreatePeriodicTaskService/TogglePeriodicTaskService 等修改的规则，会在这里被同步到 leader
+func syncJobs() {
+	periodicTasks, err := repository.ListAllActivePeriodicTasks()
+	if err != nil {
+		log.Printf("Error syncing periodic tasks: %v", err)
+		return
+	}
+
+	activeIDs := make(map[string]bool)
 	for _, pt := range periodicTasks {
-		AddJob(pt)
+		id := pt.ID.String()
+		activeIDs[id] = true
+
+		schedulerMu.Lock()
+		version, scheduled := jobVersions[id]
+		schedulerMu.Unlock()
+		if !scheduled || !version.Equal(pt.UpdatedAt) {
+			RemoveJob(id)
+			AddJob(pt)
+		}
 	}
 
-	go cronScheduler.Start()
-	log.Println("Scheduler started.")
+	schedulerMu.Lock()
+	var staleIDs []string
+	for id := range jobIDs {
+		if !activeIDs[id] {
+			staleIDs = append(staleIDs, id)
+		}
+	}
+	schedulerMu.Unlock()
+	for _, id := range staleIDs {
+		RemoveJob(id)
+	}
 }
 
-// AddJob 向运行中的调度器添加一个新作业
+// AddJob 向运行中的调度器添加一个新作业 (当前实例不是 leader 时忽略，由 leader 的同步逻辑接管)
 func AddJob(pt model.PeriodicTask) {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+	if cronScheduler == nil {
+		return
+	}
+
 	// 使用 'pt' 的副本，以避免闭包问题
 	taskToSchedule := pt
 
-	id, err := cronScheduler.AddFunc(taskToSchedule.CronExpression, func() {
-		log.Printf("Running periodic task: %s", taskToSchedule.Title)
-		createTaskFromRule(taskToSchedule)
-	})
-
+	schedule, err := parseRuleSchedule(taskToSchedule)
 	if err != nil {
 		log.Printf("Error scheduling task '%s': %v", taskToSchedule.Title, err)
 		return
 	}
+	loc := ruleLocation(taskToSchedule)
+	scheduler := cronScheduler
+	var id cron.EntryID
+	id = scheduler.Schedule(schedule, cron.FuncJob(func() {
+		log.Printf("Running periodic task: %s", taskToSchedule.Title)
+		// 本次计划的触发时间取调度器记录的 Prev (作为幂等键)，即使调度器延迟触发也不会错记为下一次触发
+		// 按规则的时区记录，保证日期戳正确
+		occurrence := scheduler.Entry(id).Prev
+		if occurrence.IsZero() {
+			log.Printf("Cannot determine scheduled time of periodic task '%s', run skipped.", taskToSchedule.Title)
+			return
+		}
+		createTaskFromRule(taskToSchedule, occurrence.In(loc), "scheduled")
+	}))
+
 	jobIDs[pt.ID.String()] = id
-	log.Printf("Scheduled task '%s' (ID: %s) with schedule: %s", pt.Title, pt.ID.String(), pt.CronExpression)
+	jobVersions[pt.ID.String()] = pt.UpdatedAt
+	log.Printf("Scheduled task '%s' (ID: %s) with schedule: %s", pt.Title, pt.ID.String(), ruleScheduleText(pt))
 }
 
 // RemoveJob 从运行中的调度器移除一个作业
 func RemoveJob(periodicTaskID string) {
+	schedulerMu.Lock()
+	defer schedulerMu.Unlock()
+	if cronScheduler == nil {
+		return
+	}
 	if entryID, ok := jobIDs[periodicTaskID]; ok {
 		cronScheduler.Remove(entryID)
 		delete(jobIDs, periodicTaskID)
+		delete(jobVersions, periodicTaskID)
 		log.Printf("Unscheduled task for rule ID: %s", periodicTaskID)
 	}
 }
 
+// catchUpMissedRuns 补偿执行服务停机期间 (until 之前) 错过的计划触发
+// 补偿窗口由系统配置 'periodic_catch_up_window_hours' 决定，从最近一次登记的触发之后开始计算
+func catchUpMissedRuns(pt model.PeriodicTask, until time.Time) {
+	windowHours := getConfigInt("periodic_catch_up_window_hours", 24)
+	if windowHours <= 0 {
+		return
+	}
+
+	schedule, err := parseRuleSchedule(pt)
+	if err != nil {
+		log.Printf("Skipping catch-up for periodic task '%s': %v", pt.Title, err)
+		return
+	}
+
+	from := until.Add(-time.Duration(windowHours) * time.Hour)
+	if pt.CreatedAt.After(from) {
+		from = pt.CreatedAt
+	}
+	if latestRun, err := repository.FindLatestPeriodicTaskRun(pt.ID, until); err == nil && latestRun.ScheduledAt.After(from) {
+		from = latestRun.ScheduledAt
+	}
+
+	caughtUp := 0
+	for occurrence := schedule.Next(from); !occurrence.After(until); occurrence = schedule.Next(occurrence) {
+		if caughtUp >= maxCatchUpRunsPerRule {
+			log.Printf("Catch-up limit reached for periodic task '%s', remaining missed runs are dropped.", pt.Title)
+			break
+		}
+		log.Printf("Catching up missed run of periodic task '%s' scheduled at %s", pt.Title, occurrence.Format(time.RFC3339))
+		createTaskFromRule(pt, occurrence, "catch_up")
+		caughtUp++
+	}
+}
+
 // createTaskFromRule 是实际执行创建任务的函数
-func createTaskFromRule(pt model.PeriodicTask) {
-	now := time.Now()
+// 每次触发都会登记到 periodic_task_runs，同一规则的同一触发时间只会生成一个任务
+func createTaskFromRule(pt model.PeriodicTask, scheduledAt time.Time, trigger string) {
+	// 1. 登记本次触发，如果已被登记过 (例如补偿与定时作业重叠)，则直接跳过
+	run := model.PeriodicTaskRun{
+		PeriodicTaskID: pt.ID,
+		ScheduledAt:    scheduledAt,
+		TriggerType:    trigger,
+		Status:         "running",
+	}
+	claimed, err := repository.ClaimPeriodicTaskRun(&run)
+	if err != nil {
+		log.Printf("Error recording run of periodic task '%s': %v", pt.Title, err)
+		return
+	}
+	if !claimed {
+		log.Printf("Skipping periodic task '%s': occurrence at %s has already been processed.", pt.Title, scheduledAt.Format(time.RFC3339))
+		return
+	}
 
 	// --- 核心校验逻辑 ---
-	// 1. 如果设置了开始时间，且当前时间早于开始时间，则不执行
-	if pt.StartDate != nil && now.Before(*pt.StartDate) {
+	// 2. 如果设置了开始时间，且触发时间早于开始时间，则不执行
+	if pt.StartDate != nil && scheduledAt.Before(*pt.StartDate) {
 		log.Printf("Skipping periodic task '%s': Not yet started.", pt.Title)
+		finishPeriodicTaskRun(run, "skipped", nil, "rule has not started yet")
 		return
 	}
-	// 2. 如果设置了结束时间，且当前时间晚于结束时间，则不执行，并考虑禁用该任务
-	if pt.EndDate != nil && now.After(*pt.EndDate) {
+	// 3. 如果设置了结束时间，且触发时间晚于结束时间，则不执行，并考虑禁用该任务
+	if pt.EndDate != nil && scheduledAt.After(*pt.EndDate) {
 		log.Printf("Skipping and deactivating periodic task '%s': It has expired.", pt.Title)
+		finishPeriodicTaskRun(run, "skipped", nil, "rule has expired")
 		// 自动禁用已过期的计划任务
 		TogglePeriodicTaskService(pt.ID, false)
 		return
 	}
-	// 为任务标题添加日期戳，方便识别
-	taskTitle := pt.Title + " - " + time.Now().Format("2006-01-02")
 
-	newTask := model.Task{
+	// 4. 按规则的暂停/节假日策略决定本次触发是跳过、顺延还是照常执行
+	// 手动触发是管理者的明确要求，不受暂停和节假日策略影响
+	plan := occurrencePlan{EffectiveAt: scheduledAt, Message: "triggered manually"}
+	if trigger != "manual" {
+		plan = planRuleOccurrence(pt, scheduledAt)
+	}
+	if plan.Skip {
+		log.Printf("Skipping periodic task '%s': %s", pt.Title, plan.Message)
+		finishPeriodicTaskRun(run, "skipped", nil, plan.Message)
+		return
+	}
+	if plan.EffectiveAt.After(time.Now()) {
+		// 顺延到之后的工作日，由 processShiftedPeriodicRuns 在到期后创建
+		log.Printf("Deferring periodic task '%s' to %s: %s", pt.Title, plan.EffectiveAt.Format(time.RFC3339), plan.Message)
+		deferPeriodicTaskRun(run, plan.EffectiveAt, plan.Message)
+		return
+	}
+
+	// 5. 创建任务，并登记结果
+	executePeriodicTaskRun(pt, run, plan.EffectiveAt, plan.Message)
+}
+
+// executePeriodicTaskRun 为一次已登记的触发创建任务，effectiveAt 为任务实际创建的日期 (用于日期戳和请假判断)
+func executePeriodicTaskRun(pt model.PeriodicTask, run model.PeriodicTaskRun, effectiveAt time.Time, note string) {
+	// 按重叠策略处理上一次仍未完成的任务 (手动触发是明确要求加做一次，不受影响)
+	if run.TriggerType != "manual" {
+		if openTask := findOpenRuleInstance(pt); openTask != nil {
+			switch pt.OverlapPolicy {
+			case "skip_if_open":
+				message := fmt.Sprintf("previous instance (task %d) is still %s, skipped", openTask.ID, openTask.Status)
+				log.Printf("Skipping periodic task '%s': %s", pt.Title, message)
+				finishPeriodicTaskRun(run, "skipped", &openTask.ID, joinRunNotes(note, message))
+				return
+			case "roll_forward":
+				message, err := rollForwardOpenInstance(pt, *openTask, effectiveAt)
+				if err != nil {
+					log.Printf("Error rolling forward task %d of periodic rule '%s': %v", openTask.ID, pt.Title, err)
+					finishPeriodicTaskRun(run, "failed", &openTask.ID, err.Error())
+					return
+				}
+				log.Printf("Periodic task '%s': %s", pt.Title, message)
+				finishPeriodicTaskRun(run, "rolled_forward", &openTask.ID, joinRunNotes(note, message))
+				return
+			}
+		}
+	}
+
+	newTask := buildTaskFromRule(pt, effectiveAt)
+	assignment := resolveRuleAssignee(pt, effectiveAt)
+	newTask.AssigneeID = assignment.AssigneeID
+	newTask.TaskKey = newTaskKey(pt.DefaultTaskTypeID)
+	if err := repository.CreateTask(&newTask); err != nil {
+		log.Printf("Error creating task from periodic rule '%s': %v", pt.Title, err)
+		finishPeriodicTaskRun(run, "failed", nil, err.Error())
+		return
+	}
+	// 任务创建成功后才推进轮值指针，失败的触发不会跳过任何成员
+	if assignment.RotationPointer != nil {
+		if err := repository.UpdateRotationPointer(pt.ID, *assignment.RotationPointer); err != nil {
+			log.Printf("Error saving rotation pointer of periodic task '%s': %v", pt.Title, err)
+		}
+	}
+	finishPeriodicTaskRun(run, "created", &newTask.ID, joinRunNotes(note, assignment.Note))
+	if newTask.AssigneeID == nil {
+		scheduleAutoAssign(newTask.ID)
+	}
+}
+
+// buildTaskFromRule 根据计划任务规则，组装本次触发要创建的任务
+func buildTaskFromRule(pt model.PeriodicTask, scheduledAt time.Time) model.Task {
+	// 为任务标题添加日期戳 (使用计划触发的日期)，方便识别
+	taskTitle := pt.Title + " - " + scheduledAt.Format("2006-01-02")
+
+	return model.Task{
 		Title:          taskTitle,
 		Description:    pt.Description,
 		Status:         "in_pool",
@@ -94,8 +507,42 @@ func createTaskFromRule(pt model.PeriodicTask) {
 		TaskTypeID:     pt.DefaultTaskTypeID,
 		CreatorID:      pt.CreatedByID,
 		AssigneeID:     pt.DefaultAssigneeID,
+		DueDate:        computeRuleDueDate(pt, scheduledAt),
 	}
-	if err := repository.CreateTask(&newTask); err != nil {
-		log.Printf("Error creating task from periodic rule '%s': %v", pt.Title, err)
+}
+
+// finishPeriodicTaskRun 登记一次计划触发的最终结果
+func finishPeriodicTaskRun(run model.PeriodicTaskRun, status string, taskID *uint, message string) {
+	updates := map[string]interface{}{
+		"status":  status,
+		"task_id": taskID,
+		"message": message,
+	}
+	if err := repository.UpdatePeriodicTaskRun(run.ID, updates); err != nil {
+		log.Printf("Error updating run %s of periodic task %s: %v", run.ID, run.PeriodicTaskID, err)
+	}
+}
+
+// deferPeriodicTaskRun 将一次触发标记为顺延，等待到达 effectiveAt 后再创建任务
+func deferPeriodicTaskRun(run model.PeriodicTaskRun, effectiveAt time.Time, message string) {
+	updates := map[string]interface{}{
+		"status":       "deferred",
+		"effective_at": effectiveAt,
+		"message":      message,
+	}
+	if err := repository.UpdatePeriodicTaskRun(run.ID, updates); err != nil {
+		log.Printf("Error deferring run %s of periodic task %s: %v", run.ID, run.PeriodicTaskID, err)
+	}
+}
+
+// ListPeriodicTaskRunsService 获取一个计划任务最近的触发记录
+func ListPeriodicTaskRunsService(periodicTaskID uuid.UUID, limit int) ([]model.PeriodicTaskRun, error) {
+	pt, err := repository.FindPeriodicTaskByID(periodicTaskID)
+	if err != nil {
+		return nil, errors.New("periodic task not found")
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
 	}
+	return repository.ListPeriodicTaskRuns(pt.ID, limit)
 }
@@ -0,0 +1,371 @@
+// internal/service/skill_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minSkillLevel = 1
+	maxSkillLevel = 5
+)
+
+// SkillRequirementInput 设置任务或任务类型技能要求时的单项输入
+type SkillRequirementInput struct {
+	SkillID  uuid.UUID `json:"skill_id" binding:"required"`
+	MinLevel int       `json:"min_level"`
+}
+
+// SkillRequirement 任务实际生效的一项技能要求
+type SkillRequirement struct {
+	SkillID   uuid.UUID `json:"skill_id"`
+	SkillName string    `json:"skill_name"`
+	MinLevel  int       `json:"min_level"`
+}
+
+// TaskSkillRequirements 任务实际生效的技能要求及其来源 ('task', 'task_type', 'none')
+type TaskSkillRequirements struct {
+	Source       string             `json:"source"`
+	Requirements []SkillRequirement `json:"requirements"`
+}
+
+// --- 技能标签库 ---
+
+func ListSkillsService() ([]model.Skill, error) {
+	return repository.ListSkills()
+}
+
+func CreateSkillService(name, description string) (model.Skill, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.Skill{}, errors.New("skill name is required")
+	}
+	skill := model.Skill{Name: name, Description: description}
+	if err := repository.CreateSkill(&skill); err != nil {
+		return model.Skill{}, errors.New("a skill with this name already exists")
+	}
+	return skill, nil
+}
+
+func UpdateSkillService(id uuid.UUID, name, description string) (model.Skill, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.Skill{}, errors.New("skill name is required")
+	}
+	skill, err := repository.FindSkillByID(id)
+	if err != nil {
+		return model.Skill{}, errors.New("skill not found")
+	}
+	skill.Name = name
+	skill.Description = description
+	if err := repository.UpdateSkill(&skill); err != nil {
+		return model.Skill{}, errors.New("a skill with this name already exists")
+	}
+	return skill, nil
+}
+
+func DeleteSkillService(id uuid.UUID) error {
+	return repository.DeleteSkill(id)
+}
+
+// --- 用户技能 ---
+
+func ListUserSkillsService(userID uuid.UUID) ([]model.UserSkill, error) {
+	return repository.ListUserSkills(userID)
+}
+
+// SetMySkillService 用户自评一项技能的熟练度
+func SetMySkillService(userID, skillID uuid.UUID, level int) error {
+	if err := validateSkillLevel(level); err != nil {
+		return err
+	}
+	if _, err := repository.FindSkillByID(skillID); err != nil {
+		return errors.New("skill not found")
+	}
+	return repository.UpsertUserSkillLevel(userID, skillID, level)
+}
+
+func DeleteMySkillService(userID, skillID uuid.UUID) error {
+	return repository.DeleteUserSkill(userID, skillID)
+}
+
+// VerifyUserSkillService 经理核实成员某项技能的熟练度，核实后以核实等级为准
+func VerifyUserSkillService(userID, skillID uuid.UUID, level int, verifierID uuid.UUID) error {
+	if err := validateSkillLevel(level); err != nil {
+		return err
+	}
+	if _, err := repository.FindUserByID(userID); err != nil {
+		return errors.New("user not found")
+	}
+	if _, err := repository.FindSkillByID(skillID); err != nil {
+		return errors.New("skill not found")
+	}
+	return repository.VerifyUserSkillLevel(userID, skillID, level, verifierID)
+}
+
+// --- 任务技能要求 ---
+
+func ListTaskTypeSkillsService(taskTypeID uuid.UUID) ([]model.TaskTypeSkill, error) {
+	return repository.ListTaskTypeSkills(taskTypeID)
+}
+
+// SetTaskTypeSkillsService 整体替换任务类型的默认技能要求
+func SetTaskTypeSkillsService(taskTypeID uuid.UUID, inputs []SkillRequirementInput) ([]model.TaskTypeSkill, error) {
+	if _, err := repository.FindTaskTypeByID(taskTypeID); err != nil {
+		return nil, errors.New("task type not found")
+	}
+	if err := validateSkillRequirements(inputs); err != nil {
+		return nil, err
+	}
+	skills := make([]model.TaskTypeSkill, 0, len(inputs))
+	for _, input := range inputs {
+		skills = append(skills, model.TaskTypeSkill{TaskTypeID: taskTypeID, SkillID: input.SkillID, MinLevel: input.MinLevel})
+	}
+	if err := repository.ReplaceTaskTypeSkills(taskTypeID, skills); err != nil {
+		return nil, err
+	}
+	return repository.ListTaskTypeSkills(taskTypeID)
+}
+
+// GetTaskSkillsService 获取任务实际生效的技能要求：任务自身的要求优先，否则继承任务类型的要求
+func GetTaskSkillsService(taskID uint) (TaskSkillRequirements, error) {
+	task, err := repository.FindTaskByID(taskID)
+	if err != nil {
+		return TaskSkillRequirements{}, errors.New("task not found")
+	}
+	return requiredSkillsForTask(task)
+}
+
+// SetTaskSkillsService 整体替换单个任务的技能要求，传入空列表表示恢复为继承任务类型的要求
+func SetTaskSkillsService(taskID uint, inputs []SkillRequirementInput) (TaskSkillRequirements, error) {
+	task, err := repository.FindTaskByID(taskID)
+	if err != nil {
+		return TaskSkillRequirements{}, errors.New("task not found")
+	}
+	if err := validateSkillRequirements(inputs); err != nil {
+		return TaskSkillRequirements{}, err
+	}
+	skills := make([]model.TaskSkill, 0, len(inputs))
+	for _, input := range inputs {
+		skills = append(skills, model.TaskSkill{TaskID: task.ID, SkillID: input.SkillID, MinLevel: input.MinLevel})
+	}
+	if err := repository.ReplaceTaskSkills(task.ID, skills); err != nil {
+		return TaskSkillRequirements{}, err
+	}
+	return requiredSkillsForTask(task)
+}
+
+// --- 技能匹配 ---
+
+// requiredSkillsForTask 计算单个任务实际生效的技能要求
+func requiredSkillsForTask(task model.Task) (TaskSkillRequirements, error) {
+	requirements, err := loadTaskRequirements([]model.Task{task})
+	if err != nil {
+		return TaskSkillRequirements{}, err
+	}
+	result := requirements[task.ID]
+	if result.Requirements == nil {
+		result.Requirements = []SkillRequirement{}
+	}
+	return result, nil
+}
+
+// loadTaskRequirements 一次性计算多个任务实际生效的技能要求 (任务ID -> 要求)
+func loadTaskRequirements(tasks []model.Task) (map[uint]TaskSkillRequirements, error) {
+	taskIDs := make([]uint, 0, len(tasks))
+	typeIDSet := make(map[uuid.UUID]bool)
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+		if task.TaskTypeID != nil {
+			typeIDSet[*task.TaskTypeID] = true
+		}
+	}
+	typeIDs := make([]uuid.UUID, 0, len(typeIDSet))
+	for id := range typeIDSet {
+		typeIDs = append(typeIDs, id)
+	}
+
+	taskSkills, err := repository.ListTaskSkillsForTasks(taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	typeSkills, err := repository.ListTaskTypeSkillsForTypes(typeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byTask := make(map[uint][]SkillRequirement)
+	for _, s := range taskSkills {
+		byTask[s.TaskID] = append(byTask[s.TaskID], SkillRequirement{SkillID: s.SkillID, SkillName: s.Skill.Name, MinLevel: s.MinLevel})
+	}
+	byType := make(map[uuid.UUID][]SkillRequirement)
+	for _, s := range typeSkills {
+		byType[s.TaskTypeID] = append(byType[s.TaskTypeID], SkillRequirement{SkillID: s.SkillID, SkillName: s.Skill.Name, MinLevel: s.MinLevel})
+	}
+
+	result := make(map[uint]TaskSkillRequirements, len(tasks))
+	for _, task := range tasks {
+		switch {
+		case len(byTask[task.ID]) > 0:
+			result[task.ID] = TaskSkillRequirements{Source: "task", Requirements: byTask[task.ID]}
+		case task.TaskTypeID != nil && len(byType[*task.TaskTypeID]) > 0:
+			result[task.ID] = TaskSkillRequirements{Source: "task_type", Requirements: byType[*task.TaskTypeID]}
+		default:
+			result[task.ID] = TaskSkillRequirements{Source: "none"}
+		}
+	}
+	return result, nil
+}
+
+// loadUserSkillLevels 获取用户每项技能的有效熟练度 (技能ID -> 等级)
+func loadUserSkillLevels(userID uuid.UUID) (map[uuid.UUID]int, error) {
+	skills, err := repository.ListUserSkills(userID)
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[uuid.UUID]int, len(skills))
+	for _, s := range skills {
+		levels[s.SkillID] = s.EffectiveLevel()
+	}
+	return levels, nil
+}
+
+// loadSkillLevelsForUsers 一次性获取多个用户的有效技能等级 (用户ID -> 技能ID -> 等级)
+func loadSkillLevelsForUsers(userIDs []uuid.UUID) (map[uuid.UUID]map[uuid.UUID]int, error) {
+	skills, err := repository.ListUserSkillsForUsers(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	levels := make(map[uuid.UUID]map[uuid.UUID]int, len(userIDs))
+	for _, s := range skills {
+		if levels[s.UserID] == nil {
+			levels[s.UserID] = make(map[uuid.UUID]int)
+		}
+		levels[s.UserID][s.SkillID] = s.EffectiveLevel()
+	}
+	return levels, nil
+}
+
+// skillGaps 列出用户不满足的技能要求，全部满足时返回空
+func skillGaps(requirements []SkillRequirement, levels map[uuid.UUID]int) []string {
+	var gaps []string
+	for _, req := range requirements {
+		level, ok := levels[req.SkillID]
+		switch {
+		case !ok:
+			gaps = append(gaps, fmt.Sprintf("requires %s (level %d), not in skill profile", req.SkillName, req.MinLevel))
+		case level < req.MinLevel:
+			gaps = append(gaps, fmt.Sprintf("requires %s level %d, has level %d", req.SkillName, req.MinLevel, level))
+		}
+	}
+	return gaps
+}
+
+// skillWarningsForAssignee 领取或指派任务时检查技能是否匹配，返回提示信息 (不阻止操作)
+func skillWarningsForAssignee(task model.Task, userID uuid.UUID) []string {
+	requirements, err := requiredSkillsForTask(task)
+	if err != nil {
+		log.Printf("Failed to load skill requirements for task %d: %v", task.ID, err)
+		return nil
+	}
+	if len(requirements.Requirements) == 0 {
+		return nil
+	}
+	levels, err := loadUserSkillLevels(userID)
+	if err != nil {
+		log.Printf("Failed to load skills for user %s: %v", userID, err)
+		return nil
+	}
+	return skillGaps(requirements.Requirements, levels)
+}
+
+// applySkillMatchMode 按系统配置的 skill_match_mode 处理执行者看到的任务池任务
+// 'hide' 隐藏技能不匹配的任务池任务，'sort' 将其排到匹配的任务之后，'off' 不处理；已指派给本人的任务不受影响
+func applySkillMatchMode(tasks []model.Task, userID uuid.UUID) []model.Task {
+	mode, err := repository.GetSystemConfigValueByKey("skill_match_mode")
+	if err != nil || (mode != "hide" && mode != "sort") {
+		return tasks
+	}
+
+	var poolTasks []model.Task
+	for _, task := range tasks {
+		if task.Status == "in_pool" {
+			poolTasks = append(poolTasks, task)
+		}
+	}
+	if len(poolTasks) == 0 {
+		return tasks
+	}
+	requirements, err := loadTaskRequirements(poolTasks)
+	if err != nil {
+		log.Printf("Failed to load skill requirements for task pool: %v", err)
+		return tasks
+	}
+	levels, err := loadUserSkillLevels(userID)
+	if err != nil {
+		log.Printf("Failed to load skills for user %s: %v", userID, err)
+		return tasks
+	}
+
+	unmatched := func(task model.Task) bool {
+		return task.Status == "in_pool" && len(skillGaps(requirements[task.ID].Requirements, levels)) > 0
+	}
+	if mode == "hide" {
+		visible := make([]model.Task, 0, len(tasks))
+		for _, task := range tasks {
+			if !unmatched(task) {
+				visible = append(visible, task)
+			}
+		}
+		return visible
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return !unmatched(tasks[i]) && unmatched(tasks[j])
+	})
+	return tasks
+}
+
+// --- 辅助函数 ---
+
+func validateSkillLevel(level int) error {
+	if level < minSkillLevel || level > maxSkillLevel {
+		return fmt.Errorf("skill level must be between %d and %d", minSkillLevel, maxSkillLevel)
+	}
+	return nil
+}
+
+// validateSkillRequirements 校验技能要求：技能必须存在且不重复，最低等级未填写时默认为 1
+func validateSkillRequirements(inputs []SkillRequirementInput) error {
+	seen := make(map[uuid.UUID]bool)
+	ids := make([]uuid.UUID, 0, len(inputs))
+	for i := range inputs {
+		if inputs[i].MinLevel == 0 {
+			inputs[i].MinLevel = minSkillLevel
+		}
+		if err := validateSkillLevel(inputs[i].MinLevel); err != nil {
+			return err
+		}
+		if seen[inputs[i].SkillID] {
+			return errors.New("duplicate skill in requirements")
+		}
+		seen[inputs[i].SkillID] = true
+		ids = append(ids, inputs[i].SkillID)
+	}
+	count, err := repository.CountSkillsByIDs(ids)
+	if err != nil {
+		return err
+	}
+	if int(count) != len(ids) {
+		return errors.New("one or more skills not found")
+	}
+	return nil
+}
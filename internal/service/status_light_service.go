@@ -0,0 +1,178 @@
+// internal/service/status_light_service.go
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	statusLightModePercentage = "percentage"
+	statusLightModeHours      = "hours"
+)
+
+// statusLightThresholds 一组状态灯阈值：负载 <= NormalMax 为 normal，<= BusyMax 为 busy，否则为 overloaded
+type statusLightThresholds struct {
+	Mode      string
+	NormalMax float64
+	BusyMax   float64
+}
+
+// statusLightSettings 一次性加载的全局阈值和按团队/角色的覆盖，避免在成员循环中重复查询数据库
+type statusLightSettings struct {
+	global    statusLightThresholds
+	overrides []model.StatusLightThreshold
+}
+
+// loadStatusLightSettings 读取全局阈值和所有覆盖配置，覆盖配置读取失败时只使用全局阈值
+func loadStatusLightSettings() statusLightSettings {
+	mode := statusLightModePercentage
+	if value, err := repository.GetSystemConfigValueByKey("status_light_mode"); err == nil && value == statusLightModeHours {
+		mode = statusLightModeHours
+	}
+	global := statusLightThresholds{Mode: mode}
+	if mode == statusLightModeHours {
+		global.NormalMax = getConfigFloat("status_light_normal_max_hours", 6)
+		global.BusyMax = getConfigFloat("status_light_busy_max_hours", 8)
+	} else {
+		global.NormalMax = getConfigFloat("status_light_normal_max_percentage", 75)
+		global.BusyMax = getConfigFloat("status_light_busy_max_percentage", 100)
+	}
+
+	overrides, err := repository.ListStatusLightThresholds()
+	if err != nil {
+		log.Printf("Failed to load status light overrides: %v", err)
+	}
+	return statusLightSettings{global: global, overrides: overrides}
+}
+
+// forUser 返回适用于某个成员的阈值：团队覆盖 > 角色覆盖 > 全局配置
+func (s statusLightSettings) forUser(user model.User) statusLightThresholds {
+	if t, ok := s.find("team", user.Team); ok {
+		return t
+	}
+	if t, ok := s.find("role", user.Role); ok {
+		return t
+	}
+	return s.global
+}
+
+// forTeam 返回适用于整个团队的阈值：团队覆盖 > 全局配置
+func (s statusLightSettings) forTeam(team string) statusLightThresholds {
+	if t, ok := s.find("team", team); ok {
+		return t
+	}
+	return s.global
+}
+
+func (s statusLightSettings) find(scopeType, scopeValue string) (statusLightThresholds, bool) {
+	scopeValue = strings.TrimSpace(scopeValue)
+	if scopeValue == "" {
+		return statusLightThresholds{}, false
+	}
+	for _, o := range s.overrides {
+		if o.ScopeType == scopeType && strings.EqualFold(o.ScopeValue, scopeValue) {
+			return statusLightThresholds{Mode: o.Mode, NormalMax: o.NormalMax, BusyMax: o.BusyMax}, true
+		}
+	}
+	return statusLightThresholds{}, false
+}
+
+// calculateStatusLight 根据阈值的判断方式，用负载工时或负载百分比计算状态灯
+func calculateStatusLight(t statusLightThresholds, loadHours, loadPercentage float64) string {
+	load := loadPercentage
+	if t.Mode == statusLightModeHours {
+		load = loadHours
+	}
+	switch {
+	case load <= 0:
+		return "idle"
+	case load <= t.NormalMax:
+		return "normal"
+	case load <= t.BusyMax:
+		return "busy"
+	default:
+		return "overloaded"
+	}
+}
+
+// --- 状态灯阈值覆盖管理 ---
+
+func ListStatusLightThresholdsService() ([]model.StatusLightThreshold, error) {
+	return repository.ListStatusLightThresholds()
+}
+
+func CreateStatusLightThresholdService(input model.StatusLightThreshold) (model.StatusLightThreshold, error) {
+	if err := validateStatusLightThreshold(&input); err != nil {
+		return model.StatusLightThreshold{}, err
+	}
+	if err := repository.CreateStatusLightThreshold(&input); err != nil {
+		return model.StatusLightThreshold{}, errors.New("a threshold for this team or role already exists")
+	}
+	return input, nil
+}
+
+func UpdateStatusLightThresholdService(id uuid.UUID, input model.StatusLightThreshold) (model.StatusLightThreshold, error) {
+	if err := validateStatusLightThreshold(&input); err != nil {
+		return model.StatusLightThreshold{}, err
+	}
+	threshold, err := repository.FindStatusLightThresholdByID(id)
+	if err != nil {
+		return model.StatusLightThreshold{}, errors.New("status light threshold not found")
+	}
+	threshold.ScopeType = input.ScopeType
+	threshold.ScopeValue = input.ScopeValue
+	threshold.Mode = input.Mode
+	threshold.NormalMax = input.NormalMax
+	threshold.BusyMax = input.BusyMax
+
+	if err := repository.UpdateStatusLightThreshold(&threshold); err != nil {
+		return model.StatusLightThreshold{}, errors.New("a threshold for this team or role already exists")
+	}
+	return threshold, nil
+}
+
+func DeleteStatusLightThresholdService(id uuid.UUID) error {
+	return repository.DeleteStatusLightThreshold(id)
+}
+
+// validateStatusLightThreshold 校验覆盖配置，并规范化范围值
+func validateStatusLightThreshold(t *model.StatusLightThreshold) error {
+	t.ScopeValue = strings.TrimSpace(t.ScopeValue)
+	switch t.ScopeType {
+	case "team":
+		if t.ScopeValue == "" {
+			return errors.New("team name is required")
+		}
+	case "role":
+		if !validUserRoles[t.ScopeValue] {
+			return errors.New("invalid role: " + t.ScopeValue)
+		}
+	default:
+		return errors.New("scope type must be 'team' or 'role'")
+	}
+	if t.Mode == "" {
+		t.Mode = statusLightModePercentage
+	}
+	if t.Mode != statusLightModePercentage && t.Mode != statusLightModeHours {
+		return errors.New("mode must be 'percentage' or 'hours'")
+	}
+	return validateStatusLightRange(t.NormalMax, t.BusyMax)
+}
+
+// validateStatusLightRange 阈值必须为正数，且 normal 上限不能超过 busy 上限
+func validateStatusLightRange(normalMax, busyMax float64) error {
+	if normalMax <= 0 || busyMax <= 0 {
+		return errors.New("status light thresholds must be positive numbers")
+	}
+	if normalMax > busyMax {
+		return errors.New("normal threshold must not exceed busy threshold")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// internal/service/task_key_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"gotasksys/internal/repository"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// taskKeyPrefixPattern 任务编号前缀：以大写字母开头，2-10位大写字母或数字
+var taskKeyPrefixPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// generateTaskKey 为指定任务类型生成下一个任务编号，例如 'OPS-128'
+// 任务类型未设置前缀 (或任务没有类型) 时，使用系统配置 'default_task_key_prefix'
+func generateTaskKey(taskTypeID *uuid.UUID) (string, error) {
+	prefix := ""
+	if taskTypeID != nil {
+		if taskType, err := repository.FindTaskTypeByID(*taskTypeID); err == nil && taskType.KeyPrefix != nil {
+			prefix = *taskType.KeyPrefix
+		}
+	}
+	if prefix == "" {
+		prefix = "TASK"
+		if defaultPrefix, err := repository.GetSystemConfigValueByKey("default_task_key_prefix"); err == nil && defaultPrefix != "" {
+			prefix = defaultPrefix
+		}
+	}
+
+	next, err := repository.NextTaskKeySequence(prefix)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%d", prefix, next), nil
+}
+
+// newTaskKey 生成任务编号，失败时只记录日志 (任务仍可通过数字ID访问)，不阻塞业务流程
+func newTaskKey(taskTypeID *uuid.UUID) *string {
+	key, err := generateTaskKey(taskTypeID)
+	if err != nil {
+		log.Printf("Warning: Failed to generate task key: %v", err)
+		return nil
+	}
+	return &key
+}
+
+// ResolveTaskKeyService 将任务编号 (如 'OPS-128'，不区分大小写) 解析为任务ID
+func ResolveTaskKeyService(key string) (uint, error) {
+	id, err := repository.FindTaskIDByKey(strings.ToUpper(strings.TrimSpace(key)))
+	if err != nil {
+		return 0, errors.New("task not found")
+	}
+	return id, nil
+}
+
+// normalizeTaskKeyPrefix 规范化并校验任务编号前缀，空字符串视为未设置
+func normalizeTaskKeyPrefix(prefix *string) (*string, error) {
+	if prefix == nil {
+		return nil, nil
+	}
+	normalized := strings.ToUpper(strings.TrimSpace(*prefix))
+	if normalized == "" {
+		return nil, nil
+	}
+	if !taskKeyPrefixPattern.MatchString(normalized) {
+		return nil, errors.New("invalid task key prefix: must be 2-10 uppercase letters or digits, starting with a letter")
+	}
+	return &normalized, nil
+}
@@ -32,7 +32,11 @@ func ListTasksService(userRole string, userID uuid.UUID) ([]model.Task, error) {
 	case "system_admin", "manager":
 		return repository.ListAllTasks()
 	case "executor":
-		return repository.ListTasksForExecutor(userID)
+		tasks, err := repository.ListTasksForExecutor(userID)
+		if err != nil {
+			return nil, err
+		}
+		return applySkillMatchMode(tasks, userID), nil
 	case "creator":
 		return repository.ListTasksForCreator(userID)
 	default:
@@ -123,74 +127,123 @@ func DeleteTaskService(taskID uint, currentUser model.User) error {
 	return repository.DeleteTask(taskID)
 }
 
-func ApproveTaskService(taskID uint, reviewerID uuid.UUID, effort int, priority string, taskTypeID uuid.UUID, difficultyRating map[string]float64) error {
+// ApproveTaskService 记录一位审批人的批准决定，当审批策略被满足时，合并所有审批意见并将任务放入任务池
+func ApproveTaskService(taskID uint, reviewerID uuid.UUID, effort int, priority string, taskTypeID uuid.UUID, difficultyRating map[string]float64, comment string) (ApprovalOutcome, error) {
 	task, err := repository.FindTaskByID(taskID)
 	if err != nil {
-		return errors.New("task not found")
+		return ApprovalOutcome{}, errors.New("task not found")
 	}
 	if task.Status != "pending_review" {
-		return errors.New("task is not in pending_review status")
+		return ApprovalOutcome{}, errors.New("task is not in pending_review status")
 	}
 	if effort <= 0 {
-		return errors.New("effort must be greater than zero")
+		return ApprovalOutcome{}, errors.New("effort must be greater than zero")
+	}
+	reviewer, err := repository.FindUserByID(reviewerID)
+	if err != nil {
+		return ApprovalOutcome{}, errors.New("reviewer not found")
 	}
 
 	// --- 新增：处理和计算技术难度分 ---
 	var finalRatingJSON datatypes.JSON
 	if difficultyRating != nil {
-		// 我们可以在这里增加校验，确保4个维度都存在
-		novelty := difficultyRating["novelty"]
-		complexity := difficultyRating["logic_complexity"]
-		impact := difficultyRating["impact_scope"]
-		collaboration := difficultyRating["collaboration_cost"]
-
-		compositeScore := (novelty + complexity + impact + collaboration) / 4.0
-		difficultyRating["composite_difficulty_score"] = compositeScore
-
-		ratingBytes, err := json.Marshal(difficultyRating)
+		ratingBytes, err := json.Marshal(withCompositeDifficultyScore(difficultyRating))
 		if err != nil {
-			return errors.New("failed to process difficulty rating")
+			return ApprovalOutcome{}, errors.New("failed to process difficulty rating")
 		}
 		finalRatingJSON = ratingBytes
 	}
 	// ------------------------------------
 
-	updates := map[string]interface{}{
-		"status":            "in_pool",
-		"reviewer_id":       reviewerID,
-		"approved_at":       time.Now(),
-		"effort":            effort,
-		"original_effort":   effort,
-		"priority":          priority,
-		"task_type_id":      &taskTypeID,     // 确保传递指针
-		"difficulty_rating": finalRatingJSON, // 保存包含综合分的完整JSON
+	// 1. 本次审批决定
+	approval := model.TaskApproval{
+		TaskID:           taskID,
+		ApproverID:       reviewerID,
+		ApproverRole:     reviewer.Role,
+		Decision:         "approved",
+		Effort:           effort,
+		Priority:         priority,
+		TaskTypeID:       &taskTypeID,
+		DifficultyRating: finalRatingJSON,
+		Comment:          comment,
+	}
+
+	// 2. 在锁定任务的事务中登记批准，并以本轮全部批准判断是否满足审批策略，未满足则任务继续停留在 'pending_review'
+	// 并发的审批、驳回按顺序执行，不会互相覆盖，也不会因各自只看到自己的批准而无法放行
+	var outcome ApprovalOutcome
+	errAlreadyApproved := errors.New("you have already approved this task")
+	recorded, err := repository.RecordTaskApproval(&approval, func(current model.Task, approvals []model.TaskApproval) (map[string]interface{}, error) {
+		// 同一审批人在同一轮中只能批准一次
+		count := 0
+		for _, a := range approvals {
+			if a.ApproverID == reviewerID {
+				count++
+			}
+		}
+		if count > 1 {
+			return nil, errAlreadyApproved
+		}
+
+		var err error
+		if outcome, err = evaluateApprovalPolicy(approvals); err != nil || !outcome.Approved {
+			return nil, err
+		}
+
+		// 3. 策略已满足：合并所有审批人的意见，任务进入任务池
+		merged := mergeApprovals(approvals)
+		updates := map[string]interface{}{
+			"status":            "in_pool",
+			"reviewer_id":       reviewerID, // 记录最终放行的审批人
+			"approved_at":       time.Now(),
+			"effort":            merged.Effort,
+			"original_effort":   merged.Effort,
+			"priority":          merged.Priority,
+			"task_type_id":      merged.TaskTypeID,       // 确保传递指针
+			"difficulty_rating": merged.DifficultyRating, // 保存包含综合分的完整JSON
+		}
+		// 任务首次确定类型时生成编号，之后保持不变
+		if current.TaskKey == nil {
+			if key := newTaskKey(merged.TaskTypeID); key != nil {
+				updates["task_key"] = *key
+			}
+		}
+		return updates, nil
+	})
+	if err != nil {
+		return ApprovalOutcome{}, err
 	}
-	return repository.UpdateTaskFields(taskID, updates)
+	if !recorded {
+		return ApprovalOutcome{}, errors.New("task is not in pending_review status")
+	}
+	if outcome.Approved {
+		scheduleAutoAssign(taskID)
+	}
+	return outcome, nil
 }
 
-// ClaimTaskService 封装了领取任务的业务逻辑
-func ClaimTaskService(taskID uint, assigneeID uuid.UUID) error {
+// ClaimTaskService 封装了领取任务的业务逻辑，返回的技能提示不会阻止领取
+func ClaimTaskService(taskID uint, assigneeID uuid.UUID) ([]string, error) {
 	// 1. 查找任务并进行业务规则校验
 	task, err := repository.FindTaskByID(taskID)
 	if err != nil {
-		return errors.New("task not found")
+		return nil, errors.New("task not found")
 	}
 	if task.Status != "in_pool" {
-		return errors.New("task is not available to be claimed")
+		return nil, errors.New("task is not available to be claimed")
 	}
 	if task.AssigneeID != nil {
-		return errors.New("task has already been assigned")
+		return nil, errors.New("task has already been assigned")
 	}
 
-	// 2. 准备要更新的字段
-	updates := map[string]interface{}{
-		"status":      "in_progress",
-		"assignee_id": assigneeID,
-		"claimed_at":  time.Now(),
+	// 2. 条件更新：任务仍在任务池且无人负责时才领取成功，避免与同时发生的指派或自动指派互相覆盖
+	claimed, err := repository.AssignPoolTaskIfUnclaimed(taskID, assigneeID, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	// 3. 更新数据库
-	return repository.UpdateTaskFields(taskID, updates)
+	if !claimed {
+		return nil, errors.New("task has already been assigned")
+	}
+	return skillWarningsForAssignee(task, assigneeID), nil
 }
 
 // CompleteTaskService 封装了完成任务并提交评价的业务逻辑 (最终版)
@@ -225,7 +278,8 @@ func CompleteTaskService(taskID uint, currentUserID uuid.UUID) error {
 
 	// 4. 准备更新
 	updates := map[string]interface{}{
-		"status": "pending_evaluation",
+		"status":       "pending_evaluation",
+		"submitted_at": time.Now(), // 记录提交评价的时间，用于计算评价等待时长
 	}
 
 	// 5. 更新数据库
@@ -289,8 +343,15 @@ func EvaluateTaskService(taskID uint, currentUser model.User, evaluationData dat
 		"status":       "completed",
 		"evaluation":   evaluationData,
 		"completed_at": time.Now(),
+		"evaluator_id": currentUser.ID, // 记录评价人
 	}
-	return repository.UpdateTaskFields(taskID, updates)
+	if err := repository.UpdateTaskFields(taskID, updates); err != nil {
+		return err
+	}
+
+	// 5. 评价人的打分习惯发生变化，重新计算校准分
+	scheduleRecalibration()
+	return nil
 }
 
 // RejectTaskService 封装了驳回任务的业务逻辑
@@ -303,12 +364,30 @@ func RejectTaskService(taskID uint, reason string, reviewerID uuid.UUID) error {
 		return errors.New("task is not in pending_review status")
 	}
 
+	// 作废本轮已有的审批记录 (重新提交后需要重新审批)，并记录驳回决定，与任务状态更新在同一事务中完成
+	rejection := model.TaskApproval{
+		TaskID:     taskID,
+		ApproverID: reviewerID,
+		Decision:   "rejected",
+		Comment:    reason,
+	}
+	if reviewer, err := repository.FindUserByID(reviewerID); err == nil {
+		rejection.ApproverRole = reviewer.Role
+	}
+
 	updates := map[string]interface{}{
 		"status":           "rejected",
 		"rejection_reason": reason,
 		"reviewer_id":      reviewerID, // 记录是谁驳回的
 	}
-	return repository.UpdateTaskFields(taskID, updates)
+	rejected, err := repository.RejectTaskWithApproval(taskID, &rejection, updates)
+	if err != nil {
+		return err
+	}
+	if !rejected {
+		return errors.New("task is not in pending_review status")
+	}
+	return nil
 }
 
 // ResubmitTaskService 封装了重新提交任务的业务逻辑
@@ -372,6 +451,7 @@ func CreateSubtaskService(parentTaskID uint, creatorID uuid.UUID, subtaskInput m
 		CreatorID:      creatorID,
 		ParentTaskID:   &parentTask.ID,
 		Status:         "in_pool",
+		TaskKey:        newTaskKey(parentTask.TaskTypeID),
 	}
 
 	// 5. 创建任务 (逻辑不变)
@@ -379,29 +459,31 @@ func CreateSubtaskService(parentTaskID uint, creatorID uuid.UUID, subtaskInput m
 	if err != nil {
 		return model.Task{}, err
 	}
+	scheduleAutoAssign(subtask.ID)
 
 	return subtask, nil
 }
 
-// AssignTaskService 封装了指派任务的业务逻辑
-func AssignTaskService(taskID uint, assigneeID uuid.UUID, managerID uuid.UUID) error {
+// AssignTaskService 封装了指派任务的业务逻辑，返回的技能提示不会阻止指派
+func AssignTaskService(taskID uint, assigneeID uuid.UUID, managerID uuid.UUID) ([]string, error) {
 	// 1. 查找任务并校验状态
 	task, err := repository.FindTaskByID(taskID)
 	if err != nil {
-		return errors.New("task not found")
+		return nil, errors.New("task not found")
 	}
 	if task.Status != "in_pool" {
-		return errors.New("task is not in the task pool to be assigned")
+		return nil, errors.New("task is not in the task pool to be assigned")
 	}
 
-	// 2. 准备更新
-	updates := map[string]interface{}{
-		"status":      "in_progress", // 任务被指派后，直接进入进行中状态
-		"assignee_id": assigneeID,
-		"claimed_at":  time.Now(), // 视同被领取
-		"reviewer_id": managerID,  // 记录下是哪位经理指派的
+	// 2. 条件更新：任务被指派后直接进入进行中状态 (视同被领取)，任务已被他人领取或自动指派时不覆盖
+	assigned, err := repository.AssignPoolTaskIfUnclaimed(taskID, assigneeID, map[string]interface{}{
+		"reviewer_id": managerID, // 记录下是哪位经理指派的
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	// 3. 更新数据库
-	return repository.UpdateTaskFields(taskID, updates)
+	if !assigned {
+		return nil, errors.New("task has already been assigned")
+	}
+	return skillWarningsForAssignee(task, assigneeID), nil
 }
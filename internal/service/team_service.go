@@ -0,0 +1,114 @@
+// internal/service/team_service.go
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"gotasksys/internal/repository"
+)
+
+// TeamWorkload 单个团队的汇总负载、产能、超期和绩效情况
+// Team 为空字符串的分组表示尚未设置团队的成员
+type TeamWorkload struct {
+	Team                string  `json:"team"`
+	MemberCount         int     `json:"member_count"`
+	InProgressTaskCount int     `json:"in_progress_task_count"`
+	TotalLoadHours      float64 `json:"total_load_hours"`
+	TotalCapacityHours  float64 `json:"total_capacity_hours"`
+	LoadPercentage      float64 `json:"load_percentage"`
+	StatusLight         string  `json:"status_light"`
+	OverloadedMembers   int     `json:"overloaded_members"`
+	IdleMembers         int     `json:"idle_members"`
+	OverdueTaskCount    int     `json:"overdue_task_count"`
+	MembersWithOverdue  int     `json:"members_with_overdue"`
+	PoolTaskCount       int64   `json:"pool_task_count"`     // 该团队成员创建、仍在任务池中等待领取的任务数
+	PoolEffortHours     float64 `json:"pool_effort_hours"`   // 上述任务的总工时
+	AvgCompositeScore   float64 `json:"avg_composite_score"` // 团队内有绩效数据的执行者的平均综合分
+	RatedMembers        int     `json:"rated_members"`
+}
+
+// GetTeamWorkloadService 按 User.Team 汇总所有成员的看板数据，用于在团队之间平衡工作
+func GetTeamWorkloadService() ([]TeamWorkload, error) {
+	statuses, err := GetPersonnelStatusService("")
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]*TeamWorkload)
+	// 团队名大小写不敏感，展示时保留第一次出现的写法
+	teamOf := func(name string) *TeamWorkload {
+		name = strings.TrimSpace(name)
+		key := strings.ToLower(name)
+		if team, ok := teams[key]; ok {
+			return team
+		}
+		team := &TeamWorkload{Team: name}
+		teams[key] = team
+		return team
+	}
+
+	scoreSums := make(map[*TeamWorkload]float64)
+	for _, status := range statuses {
+		team := teamOf(status.User.Team)
+		team.MemberCount++
+		team.InProgressTaskCount += len(status.InProgressTasks)
+		team.TotalLoadHours += status.CurrentLoadHours
+		team.TotalCapacityHours += status.DailyCapacityHours
+		team.OverdueTaskCount += status.OverdueTaskCount
+		if status.HasOverdueTask {
+			team.MembersWithOverdue++
+		}
+		switch status.StatusLight {
+		case "overloaded":
+			team.OverloadedMembers++
+		case "idle":
+			team.IdleMembers++
+		}
+		if status.PerformanceMetrics != nil && status.PerformanceMetrics.CompositeScore > 0 {
+			scoreSums[team] += status.PerformanceMetrics.CompositeScore
+			team.RatedMembers++
+		}
+	}
+
+	// 任务池需求按创建人所在团队归属，没有成员在看板上的团队也会出现
+	demands, err := repository.SummarizePoolDemandByTeam()
+	if err != nil {
+		return nil, err
+	}
+	for _, demand := range demands {
+		team := teamOf(demand.Team)
+		team.PoolTaskCount += demand.TaskCount
+		team.PoolEffortHours += demand.EffortHours
+	}
+
+	lightSettings := loadStatusLightSettings()
+	result := make([]TeamWorkload, 0, len(teams))
+	for _, team := range teams {
+		if team.TotalCapacityHours > 0 {
+			team.LoadPercentage = (team.TotalLoadHours / team.TotalCapacityHours) * 100
+		}
+		// 按工时判断时使用人均负载工时，与单个成员的阈值口径保持一致
+		avgLoadHours := 0.0
+		if team.MemberCount > 0 {
+			avgLoadHours = team.TotalLoadHours / float64(team.MemberCount)
+		}
+		team.StatusLight = calculateStatusLight(lightSettings.forTeam(team.Team), avgLoadHours, team.LoadPercentage)
+		if team.RatedMembers > 0 {
+			team.AvgCompositeScore = scoreSums[team] / float64(team.RatedMembers)
+		}
+		result = append(result, *team)
+	}
+
+	// 负载最高的团队排在前面，未设置团队的分组放在最后
+	sort.Slice(result, func(i, j int) bool {
+		if (result[i].Team == "") != (result[j].Team == "") {
+			return result[j].Team == ""
+		}
+		if result[i].LoadPercentage != result[j].LoadPercentage {
+			return result[i].LoadPercentage > result[j].LoadPercentage
+		}
+		return result[i].Team < result[j].Team
+	})
+	return result, nil
+}
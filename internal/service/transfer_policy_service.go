@@ -0,0 +1,137 @@
+// internal/service/transfer_policy_service.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"gotasksys/internal/model"
+	"gotasksys/internal/repository"
+	"gotasksys/pkg/apierror"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// transferPolicyDecision 记录转交策略对一次转交的判定结果
+type transferPolicyDecision struct {
+	RequiresApproval bool
+	Reason           string
+}
+
+// evaluateTransferPolicy 根据系统配置的转交策略，判定一次转交是直接交给接收人、需要经理审批，还是直接拒绝
+// 规则：
+// 1. 接收人角色不在 'transfer_eligible_roles' 中 -> 拒绝
+// 2. 跨团队转交 (且 'transfer_cross_team_requires_approval' 为 true) -> 需要审批
+// 3. 接收人当前负载超过 'transfer_overload_threshold_percentage' -> 需要审批
+// 4. 其余情况 (同团队或允许跨团队，且接收人未超载) -> 直接交给接收人，原因分别记为 'same_team' 或 'cross_team_allowed'
+func evaluateTransferPolicy(initiator, recipient model.User) (transferPolicyDecision, error) {
+	// 1. 角色资格校验
+	eligibleRoles := getConfigList("transfer_eligible_roles", []string{"executor", "manager"})
+	isEligible := false
+	for _, role := range eligibleRoles {
+		if recipient.Role == role {
+			isEligible = true
+			break
+		}
+	}
+	if !isEligible {
+		return transferPolicyDecision{}, fmt.Errorf("transfer rejected by policy: recipient role '%s' is not eligible", recipient.Role)
+	}
+
+	var reasons []string
+
+	// 2. 团队校验：只有双方都填写了团队且团队一致，才视为同团队
+	sameTeam := initiator.Team != "" && initiator.Team == recipient.Team
+	if !sameTeam && getConfigBool("transfer_cross_team_requires_approval", true) {
+		reasons = append(reasons, "cross_team")
+	}
+
+	// 3. 接收人负载校验 (复用人员看板的负载算法)
+	threshold := getConfigFloat("transfer_overload_threshold_percentage", 100)
+	if threshold > 0 {
+		loadPercentage, err := getUserLoadPercentage(recipient)
+		if err != nil {
+			log.Printf("Failed to calculate load for transfer recipient %s: %v", recipient.Username, err)
+		} else if loadPercentage > threshold {
+			reasons = append(reasons, "recipient_overloaded")
+		}
+	}
+
+	if len(reasons) > 0 {
+		return transferPolicyDecision{RequiresApproval: true, Reason: strings.Join(reasons, ",")}, nil
+	}
+	if !sameTeam {
+		// 跨团队，但系统配置允许跨团队转交无需审批
+		return transferPolicyDecision{RequiresApproval: false, Reason: "cross_team_allowed"}, nil
+	}
+	return transferPolicyDecision{RequiresApproval: false, Reason: "same_team"}, nil
+}
+
+// ListTransfersAwaitingApprovalService 获取所有等待经理审批的转交请求
+func ListTransfersAwaitingApprovalService() ([]model.TaskTransfer, error) {
+	return repository.ListTransfersAwaitingApproval()
+}
+
+// ApproveTransferByManagerService 经理批准一个需要审批的转交请求，批准后转交进入接收人的待处理状态
+func ApproveTransferByManagerService(transferID, managerID uuid.UUID, comment string) error {
+	transfer, err := findTransferAwaitingApproval(transferID, managerID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"status":            "pending",
+		"approver_id":       managerID,
+		"approval_decision": "approved",
+		"approval_comment":  comment,
+		"decided_at":        time.Now(),
+	}
+	changed, err := repository.TransitionTransfer(transfer.ID, []string{"pending_approval"}, updates, transfer.TaskID, nil, nil)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return errors.New("transfer request is not awaiting manager approval")
+	}
+	return nil
+}
+
+// DenyTransferByManagerService 经理驳回一个需要审批的转交请求，任务退回原负责人
+func DenyTransferByManagerService(transferID, managerID uuid.UUID, comment string) error {
+	transfer, err := findTransferAwaitingApproval(transferID, managerID)
+	if err != nil {
+		return err
+	}
+
+	updates := map[string]interface{}{
+		"status":            "denied",
+		"approver_id":       managerID,
+		"approval_decision": "denied",
+		"approval_comment":  comment,
+		"decided_at":        time.Now(),
+	}
+	if err := closeTransfer(transfer, []string{"pending_approval"}, updates); err != nil {
+		if errors.Is(err, apierror.ErrTransferStatusConflict) {
+			return errors.New("transfer request is not awaiting manager approval")
+		}
+		return err
+	}
+	return nil
+}
+
+// findTransferAwaitingApproval 查找转交记录并校验其处于待审批状态，且审批人不是转交的发起人
+func findTransferAwaitingApproval(transferID, managerID uuid.UUID) (model.TaskTransfer, error) {
+	transfer, err := repository.FindTransferByID(transferID)
+	if err != nil {
+		return model.TaskTransfer{}, errors.New("transfer request not found")
+	}
+	if transfer.FromUserID == managerID {
+		return model.TaskTransfer{}, errors.New("permission denied: you cannot decide on a transfer you initiated")
+	}
+	if transfer.Status != "pending_approval" {
+		return model.TaskTransfer{}, errors.New("transfer request is not awaiting manager approval")
+	}
+	return transfer, nil
+}
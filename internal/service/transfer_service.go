@@ -7,11 +7,15 @@ import (
 	"gotasksys/internal/repository"
 	"gotasksys/pkg/apierror"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-func InitiateTransferService(taskID uint, initiatorID, newAssigneeID uuid.UUID, effortSpent int) (model.TaskTransfer, error) {
+// InitiateTransferService 发起一次任务转交，交接说明为必填项
+func InitiateTransferService(taskID uint, initiatorID, newAssigneeID uuid.UUID, effortSpent int, handoffNotes string) (model.TaskTransfer, error) {
 	task, err := repository.FindTaskByID(taskID)
 	if err != nil {
 		return model.TaskTransfer{}, errors.New("task not found")
@@ -22,22 +26,49 @@ func InitiateTransferService(taskID uint, initiatorID, newAssigneeID uuid.UUID,
 	if task.AssigneeID == nil || *task.AssigneeID != initiatorID {
 		return model.TaskTransfer{}, errors.New("permission denied: you are not the current assignee")
 	}
+	if strings.TrimSpace(handoffNotes) == "" {
+		return model.TaskTransfer{}, errors.New("handoff notes are required when initiating a transfer")
+	}
+	if newAssigneeID == initiatorID {
+		return model.TaskTransfer{}, errors.New("cannot transfer a task to yourself")
+	}
+
+	// 根据转交策略判定：直接交给接收人、需要经理审批，或直接拒绝
+	initiator, err := repository.FindUserByID(initiatorID)
+	if err != nil {
+		return model.TaskTransfer{}, errors.New("initiator not found")
+	}
+	recipient, err := repository.FindUserByID(newAssigneeID)
+	if err != nil {
+		return model.TaskTransfer{}, errors.New("recipient not found")
+	}
+	decision, err := evaluateTransferPolicy(initiator, recipient)
+	if err != nil {
+		return model.TaskTransfer{}, err
+	}
 
 	transfer := model.TaskTransfer{
 		TaskID:                 taskID,
 		FromUserID:             initiatorID,
 		ToUserID:               newAssigneeID,
 		EffortSpentByInitiator: effortSpent,
+		HandoffNotes:           handoffNotes,
+		Status:                 "pending",
+		RequiresApproval:       decision.RequiresApproval,
+		PolicyReason:           decision.Reason,
 	}
-
-	if err := repository.CreateTransfer(&transfer); err != nil {
-		return model.TaskTransfer{}, err
+	if decision.RequiresApproval {
+		transfer.Status = "pending_approval"
 	}
 
-	updates := map[string]interface{}{"status": "pending_transfer"}
-	if err := repository.UpdateTaskFields(taskID, updates); err != nil {
+	// 任务状态与转交记录在同一事务中变更，并发发起的第二次转交会因任务已不在进行中而失败
+	created, err := repository.CreateTransferForTask(&transfer, map[string]interface{}{"status": "pending_transfer"})
+	if err != nil {
 		return model.TaskTransfer{}, err
 	}
+	if !created {
+		return model.TaskTransfer{}, errors.New("task is not in progress")
+	}
 
 	return transfer, nil
 }
@@ -61,12 +92,7 @@ func RespondToTransferService(transferID, respondentID uuid.UUID, action string)
 	if action == "accept" {
 		// --- 【接受转交】的完整逻辑 ---
 
-		// a. 将转交记录的状态更新为 'accepted'
-		if err := repository.UpdateTransferStatus(transferID, "accepted"); err != nil {
-			return err
-		}
-
-		// b. 更新主任务的负责人、状态和重新计算的工时
+		// a. 计算主任务重新分配后的工时
 		task, err := repository.FindTaskByID(transfer.TaskID)
 		if err != nil {
 			return err
@@ -80,31 +106,30 @@ func RespondToTransferService(transferID, respondentID uuid.UUID, action string)
 			"status":      "in_progress",
 			"effort":      newEffort,
 		}
-		if err := repository.UpdateTaskFields(transfer.TaskID, mainTaskUpdates); err != nil {
-			return err
-		}
 
-		// c. 【核心修正】级联转交子任务
-		// 将所有隶属于该主任务、且负责人是原负责人(FromUserID)的子任务，一并转交给新负责人(respondentID)
-		err = repository.BatchUpdateSubtasksAssignee(transfer.TaskID, transfer.FromUserID, respondentID)
+		// b. 在同一事务中将转交记录更新为 'accepted'、更新主任务的负责人、状态和工时，并将子任务的级联转交放入后台作业队列
+		// 只有转交仍处于 'pending' 时才会生效，避免与过期、取消等并发操作互相覆盖
+		// 级联转交：所有隶属于该主任务、且负责人是原负责人(FromUserID)的子任务，一并转交给新负责人(respondentID)，失败时作业会自动重试
+		enqueueCascade := func(tx *gorm.DB) error {
+			return EnqueueJobTx(tx, JobTypeCascadeSubtaskAssignee, CascadeSubtaskAssigneePayload{
+				ParentTaskID: transfer.TaskID,
+				FromUserID:   transfer.FromUserID,
+				ToUserID:     respondentID,
+			})
+		}
+		changed, err := repository.TransitionTransfer(transferID, []string{"pending"},
+			map[string]interface{}{"status": "accepted"}, transfer.TaskID, mainTaskUpdates, enqueueCascade)
 		if err != nil {
-			// 即使这一步失败，主任务的转交也已完成，所以我们只记录日志而不返回阻塞性错误
-			log.Printf("Warning: Failed to cascade subtask assignee update for parent task %d. Error: %v", transfer.TaskID, err)
+			return err
+		}
+		if !changed {
+			return apierror.ErrTransferStatusConflict
 		}
 
 	} else if action == "reject" {
 		// --- 【拒绝转交】的逻辑 ---
-		// 将转交记录状态更新为 'rejected'
-		if err := repository.UpdateTransferStatus(transferID, "rejected"); err != nil {
-			return err
-		}
-		// 将原任务的状态恢复为 'in_progress'
-		updates := map[string]interface{}{"status": "in_progress"}
-		if err := repository.UpdateTaskFields(transfer.TaskID, updates); err != nil {
-			log.Printf("Warning: Transfer status set to 'rejected' but failed to update task %d status to 'in_progress': %v", transfer.TaskID, err)
-			return err
-		}
-		return nil
+		// 将转交记录状态更新为 'rejected'，并将原任务的状态恢复为 'in_progress'
+		return closeTransfer(transfer, []string{"pending"}, map[string]interface{}{"status": "rejected"})
 
 	} else {
 		return errors.New("invalid action specified")
@@ -120,7 +145,7 @@ func CancelTransferService(transferID, initiatorID uuid.UUID) error {
 	if err != nil {
 		return errors.New("transfer request not found")
 	}
-	if transfer.Status != "pending" {
+	if transfer.Status != "pending" && transfer.Status != "pending_approval" {
 		return errors.New("transfer request is no longer pending and cannot be cancelled")
 	}
 	// 权限校验：只有发起人自己才能取消
@@ -128,12 +153,61 @@ func CancelTransferService(transferID, initiatorID uuid.UUID) error {
 		return errors.New("permission denied: you are not the initiator of this transfer")
 	}
 
-	// 2. 将转交记录的状态更新为 'cancelled'
-	if err := repository.UpdateTransferStatus(transferID, "cancelled"); err != nil {
-		return err
+	// 2. 结束转交请求，并将原任务的状态恢复为 'in_progress'
+	return closeTransfer(transfer, pendingTransferStatuses, map[string]interface{}{"status": "cancelled"})
+}
+
+// ListIncomingTransfersService 获取我收到的转交请求 (收件箱)
+func ListIncomingTransfersService(userID uuid.UUID, status string) ([]model.TaskTransfer, error) {
+	return repository.ListIncomingTransfers(userID, status)
+}
+
+// ListOutgoingTransfersService 获取我发起的转交请求 (发件箱)
+func ListOutgoingTransfersService(userID uuid.UUID, status string) ([]model.TaskTransfer, error) {
+	return repository.ListOutgoingTransfers(userID, status)
+}
+
+// ExpireStaleTransfersService 自动取消超过有效期仍未处理的转交请求
+// 有效期由系统配置 'transfer_expiry_hours' 决定，从最近一次状态变化 (创建或经理审批) 开始计算，配置为0或负数时表示永不过期
+func ExpireStaleTransfersService() {
+	expiryHours := getConfigInt("transfer_expiry_hours", 72)
+	if expiryHours <= 0 {
+		return
+	}
+
+	deadline := time.Now().Add(-time.Duration(expiryHours) * time.Hour)
+	transfers, err := repository.ListPendingTransfersIdleSince(deadline)
+	if err != nil {
+		log.Printf("Error fetching stale transfers: %v", err)
+		return
 	}
 
-	// 3. 将原任务的状态恢复为 'in_progress'
-	updates := map[string]interface{}{"status": "in_progress"}
-	return repository.UpdateTaskFields(transfer.TaskID, updates)
+	for _, transfer := range transfers {
+		err := closeTransfer(transfer, pendingTransferStatuses, map[string]interface{}{"status": "expired"})
+		if errors.Is(err, apierror.ErrTransferStatusConflict) {
+			continue // 在此期间已被接受、拒绝或取消
+		}
+		if err != nil {
+			log.Printf("Failed to expire transfer %s for task %d: %v", transfer.ID, transfer.TaskID, err)
+			continue
+		}
+		log.Printf("Transfer %s for task %d expired after %d idle hours.", transfer.ID, transfer.TaskID, expiryHours)
+	}
+}
+
+// pendingTransferStatuses 尚未处理完的转交状态
+var pendingTransferStatuses = []string{"pending", "pending_approval"}
+
+// closeTransfer 结束一个转交请求 (拒绝、取消、过期或经理驳回)，并在同一事务中将原任务恢复为 'in_progress'
+// 只有转交仍处于 fromStatuses 之一时才会生效，否则返回 ErrTransferStatusConflict 且不修改任务
+func closeTransfer(transfer model.TaskTransfer, fromStatuses []string, updates map[string]interface{}) error {
+	changed, err := repository.TransitionTransfer(transfer.ID, fromStatuses, updates,
+		transfer.TaskID, map[string]interface{}{"status": "in_progress"}, nil)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return apierror.ErrTransferStatusConflict
+	}
+	return nil
 }
@@ -0,0 +1,22 @@
+-- 000015_add_transfer_inbox_and_expiry.sql
+
+-- 1. 为转交记录增加交接说明字段
+ALTER TABLE task_transfers ADD COLUMN handoff_notes TEXT;
+
+-- 为收件箱/发件箱查询创建索引
+CREATE INDEX idx_task_transfers_to_user_id ON task_transfers (to_user_id, status);
+
+CREATE INDEX idx_task_transfers_from_user_id ON task_transfers (from_user_id, status);
+
+-- 2. 插入转交请求的默认过期时长配置
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'transfer_expiry_hours',
+        '72',
+        '待处理的转交请求在多少小时后自动取消'
+    );
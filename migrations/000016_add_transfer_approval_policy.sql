@@ -0,0 +1,33 @@
+-- 000016_add_transfer_approval_policy.sql
+
+-- 1. 为转交记录增加策略判定与经理审批相关字段
+ALTER TABLE task_transfers
+ADD COLUMN requires_approval BOOLEAN NOT NULL DEFAULT FALSE,
+ADD COLUMN policy_reason VARCHAR(255),
+ADD COLUMN approver_id UUID REFERENCES users (id),
+ADD COLUMN approval_decision VARCHAR(50), -- 'approved', 'denied'
+ADD COLUMN approval_comment TEXT,
+ADD COLUMN decided_at TIMESTAMPTZ;
+
+-- 2. 插入转交策略的默认配置
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'transfer_cross_team_requires_approval',
+        'true',
+        '跨团队转交是否需要经理审批'
+    ),
+    (
+        'transfer_overload_threshold_percentage',
+        '100',
+        '接收人负载百分比超过该值时，转交需要经理审批（0表示不检查）'
+    ),
+    (
+        'transfer_eligible_roles',
+        'executor,manager',
+        '允许作为转交接收人的角色，逗号分隔'
+    );
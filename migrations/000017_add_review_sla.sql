@@ -0,0 +1,39 @@
+-- 000017_add_review_sla.sql
+
+-- 1. 记录任务提交评价的时间与评价人，用于计算评价等待时长和评价人统计
+ALTER TABLE tasks
+ADD COLUMN submitted_at TIMESTAMPTZ,
+ADD COLUMN evaluator_id UUID REFERENCES users (id);
+
+-- 2. 审核SLA策略表：可按优先级和/或任务类型配置审批、评价的目标时长
+CREATE TABLE review_sla_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    stage VARCHAR(50) NOT NULL, -- 'approval', 'evaluation'
+    priority VARCHAR(50), -- 为空表示适用于所有优先级
+    task_type_id UUID REFERENCES task_types (id) ON DELETE CASCADE, -- 为空表示适用于所有任务类型
+    target_hours FLOAT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_tasks_reviewer_id ON tasks (reviewer_id);
+
+CREATE INDEX idx_tasks_evaluator_id ON tasks (evaluator_id);
+
+-- 3. 未匹配到任何策略时使用的默认SLA
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'review_sla_default_approval_hours',
+        '24',
+        '待审批任务的默认SLA目标时长（小时）'
+    ),
+    (
+        'review_sla_default_evaluation_hours',
+        '48',
+        '待评价任务的默认SLA目标时长（小时）'
+    );
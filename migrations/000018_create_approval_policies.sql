@@ -0,0 +1,32 @@
+-- 000018_create_approval_policies.sql
+
+-- 1. 多级审批策略表：工时超过阈值或属于特定任务类型的任务，需要N个审批 (或特定角色的审批) 才能进入任务池
+CREATE TABLE approval_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    name VARCHAR(255) NOT NULL,
+    min_effort INT, -- 为空表示不按工时匹配
+    task_type_id UUID REFERENCES task_types (id) ON DELETE CASCADE, -- 为空表示不按任务类型匹配
+    required_approvals INT NOT NULL DEFAULT 1,
+    required_role VARCHAR(50), -- 为空表示不要求特定角色
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 2. 任务审批记录表：记录每位审批人的决定、工时建议和难度评分
+CREATE TABLE task_approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    approver_id UUID NOT NULL REFERENCES users (id),
+    approver_role VARCHAR(50) NOT NULL,
+    decision VARCHAR(50) NOT NULL, -- 'approved', 'rejected'
+    effort INT,
+    priority VARCHAR(50),
+    task_type_id UUID REFERENCES task_types (id),
+    difficulty_rating JSONB,
+    comment TEXT,
+    is_superseded BOOLEAN NOT NULL DEFAULT FALSE, -- 任务被驳回后，本轮的审批记录作废
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_approvals_task_id ON task_approvals (task_id);
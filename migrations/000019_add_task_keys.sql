@@ -0,0 +1,27 @@
+-- 000019_add_task_keys.sql
+
+-- 1. 为任务类型增加任务编号前缀，例如 'OPS', 'DEV'
+ALTER TABLE task_types ADD COLUMN key_prefix VARCHAR(16) UNIQUE;
+
+-- 2. 每个前缀独立的编号序列
+CREATE TABLE task_key_sequences (
+    prefix VARCHAR(16) PRIMARY KEY,
+    last_value BIGINT NOT NULL DEFAULT 0
+);
+
+-- 3. 任务的人类可读编号，例如 'OPS-128'
+-- 编号在任务首次确定任务类型时生成 (审批通过、计划任务生成、子任务创建)，之后即使修改任务类型也保持不变
+ALTER TABLE tasks ADD COLUMN task_key VARCHAR(32) UNIQUE;
+
+-- 4. 任务类型未设置前缀时使用的默认前缀
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'default_task_key_prefix',
+        'TASK',
+        '任务类型未设置编号前缀时使用的默认前缀'
+    );
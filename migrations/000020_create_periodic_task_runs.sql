@@ -0,0 +1,31 @@
+-- 000020_create_periodic_task_runs.sql
+
+-- 计划任务执行记录表：记录每一次计划触发 (occurrence) 的结果和生成的任务
+-- (periodic_task_id, scheduled_at) 唯一约束保证同一规则的同一次触发最多只生成一个任务
+CREATE TABLE periodic_task_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    periodic_task_id UUID NOT NULL REFERENCES periodic_tasks (id) ON DELETE CASCADE,
+    scheduled_at TIMESTAMPTZ NOT NULL, -- 计划触发的时间点
+    trigger_type VARCHAR(50) NOT NULL, -- 'scheduled', 'catch_up'
+    status VARCHAR(50) NOT NULL, -- 'running', 'created', 'skipped', 'failed'
+    task_id BIGINT REFERENCES tasks (id) ON DELETE SET NULL,
+    message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (periodic_task_id, scheduled_at)
+);
+
+CREATE INDEX idx_periodic_task_runs_scheduled_at ON periodic_task_runs (periodic_task_id, scheduled_at DESC);
+
+-- 服务启动时补偿执行错过的计划触发的时间窗口
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'periodic_catch_up_window_hours',
+        '24',
+        '服务启动时，补偿执行多少小时内错过的计划任务（0表示不补偿）'
+    );
@@ -0,0 +1,10 @@
+-- 000021_create_scheduler_leases.sql
+
+-- 调度器租约表：多实例部署时，只有持有租约的实例 (leader) 执行计划任务
+-- leader 定期续约；租约过期后 (例如 leader 宕机) 其他实例可以接管
+CREATE TABLE scheduler_leases (
+    name VARCHAR(100) PRIMARY KEY,
+    holder_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,21 @@
+-- 000022_add_periodic_holiday_leave_policies.sql
+
+-- 计划任务的节假日策略：触发时间落在节假日 (holidays 表) 时
+-- 'none' 照常创建, 'skip' 跳过, 'next_working_day' 顺延到下一个工作日, 'previous_working_day' 提前到上一个工作日
+ALTER TABLE periodic_tasks
+ADD COLUMN holiday_policy VARCHAR(30) NOT NULL DEFAULT 'none';
+
+-- 计划任务的请假策略：默认负责人在当天请假时
+-- 'none' 照常指派, 'pool' 放入任务池, 'backup' 指派给备岗人员 (备岗人员也请假时放入任务池)
+ALTER TABLE periodic_tasks
+ADD COLUMN leave_fallback VARCHAR(30) NOT NULL DEFAULT 'none';
+
+ALTER TABLE periodic_tasks
+ADD COLUMN backup_assignee_id UUID REFERENCES users (id) ON DELETE SET NULL;
+
+-- 顺延/提前的触发记录：effective_at 为任务实际创建的时间
+ALTER TABLE periodic_task_runs ADD COLUMN effective_at TIMESTAMPTZ;
+
+CREATE INDEX idx_periodic_task_runs_deferred ON periodic_task_runs (effective_at)
+WHERE
+    status = 'deferred';
@@ -0,0 +1,9 @@
+-- 000023_add_periodic_due_offset.sql
+
+-- 计划任务生成任务的截止日期偏移 (按工作日日历计算)
+-- 'none' 不设截止日期, 'working_days' 创建后第 N 个工作日, 'end_of_week' 本周最后一个工作日, 'end_of_month' 本月最后一个工作日
+ALTER TABLE periodic_tasks
+ADD COLUMN due_offset_type VARCHAR(30) NOT NULL DEFAULT 'none';
+
+ALTER TABLE periodic_tasks
+ADD COLUMN due_offset_value INT NOT NULL DEFAULT 0;
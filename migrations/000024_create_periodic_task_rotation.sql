@@ -0,0 +1,19 @@
+-- 000024_create_periodic_task_rotation.sql
+
+-- 计划任务的负责人轮值策略
+-- 'none' 使用默认负责人, 'round_robin' 严格轮流, 'skip_on_leave' 轮流但跳过当天请假的成员, 'least_loaded' 指派给当前负载最低的成员
+ALTER TABLE periodic_tasks
+ADD COLUMN rotation_strategy VARCHAR(30) NOT NULL DEFAULT 'none';
+
+-- 轮值指针：轮值池中下一位成员的位置，持久化以保证服务重启后不会从头开始
+ALTER TABLE periodic_tasks
+ADD COLUMN rotation_pointer INT NOT NULL DEFAULT 0;
+
+-- 计划任务轮值池成员
+CREATE TABLE periodic_task_rotation_members (
+    periodic_task_id UUID NOT NULL REFERENCES periodic_tasks (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (periodic_task_id, user_id)
+);
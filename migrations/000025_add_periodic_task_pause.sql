@@ -0,0 +1,4 @@
+-- 000025_add_periodic_task_pause.sql
+
+-- 计划任务暂停截止时间：在此之前的触发都会被跳过，之后自动恢复 (无需手动重新启用)
+ALTER TABLE periodic_tasks ADD COLUMN paused_until TIMESTAMPTZ;
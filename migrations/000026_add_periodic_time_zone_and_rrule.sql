@@ -0,0 +1,11 @@
+-- 000026_add_periodic_time_zone_and_rrule.sql
+
+-- 计划任务的时区 (IANA 名称，如 'Asia/Shanghai')，为空时使用服务器本地时区
+ALTER TABLE periodic_tasks
+ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT '';
+
+-- 日历式重复规则 (RFC 5545 RRULE)，与 cron_expression 二选一，可表达"每月第二个周二"、"每月最后一个工作日"等
+ALTER TABLE periodic_tasks ADD COLUMN rrule TEXT NOT NULL DEFAULT '';
+
+-- 使用 RRULE 的规则不再需要Cron表达式
+ALTER TABLE periodic_tasks ALTER COLUMN cron_expression SET DEFAULT '';
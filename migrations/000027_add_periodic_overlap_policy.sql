@@ -0,0 +1,8 @@
+-- 000027_add_periodic_overlap_policy.sql
+
+-- 计划任务的重叠策略：上一次生成的任务仍未完成 (in_pool / in_progress) 时
+-- 'always' 照常创建, 'skip_if_open' 跳过本次, 'roll_forward' 不新建任务，而是顺延旧任务的截止日期并提升优先级
+ALTER TABLE periodic_tasks
+ADD COLUMN overlap_policy VARCHAR(30) NOT NULL DEFAULT 'always';
+
+CREATE INDEX idx_periodic_task_runs_task_id ON periodic_task_runs (task_id);
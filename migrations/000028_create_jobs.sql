@@ -0,0 +1,25 @@
+-- 000028_create_jobs.sql
+
+-- 通用后台作业队列：通知、报表、级联更新等异步工作在这里排队，服务重启后不会丢失
+-- status: 'pending' 等待执行 (包括等待重试), 'running' 执行中, 'succeeded' 成功, 'dead' 重试耗尽 (死信)
+CREATE TABLE jobs (
+    id BIGSERIAL PRIMARY KEY,
+    job_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(30) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 5,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT now(), -- 最早可执行时间 (重试时按指数退避推后)
+    last_error TEXT,
+    locked_by VARCHAR(255),
+    locked_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_jobs_pending ON jobs (run_at, id)
+WHERE
+    status = 'pending';
+
+CREATE INDEX idx_jobs_status ON jobs (status, created_at DESC);
@@ -0,0 +1,48 @@
+-- 000029_add_status_light_thresholds.sql
+
+-- 1. 状态灯阈值：负载 <= normal_max 为 normal，<= busy_max 为 busy，超过 busy_max 为 overloaded
+-- mode: 'percentage' 按负载百分比判断, 'hours' 按当天负载工时判断
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'status_light_mode',
+        'percentage',
+        '状态灯判断方式：percentage（负载百分比）或 hours（负载工时）'
+    ),
+    (
+        'status_light_normal_max_percentage',
+        '75',
+        '负载百分比不超过该值时状态灯为 normal'
+    ),
+    (
+        'status_light_busy_max_percentage',
+        '100',
+        '负载百分比不超过该值时状态灯为 busy，超过则为 overloaded'
+    ),
+    (
+        'status_light_normal_max_hours',
+        '6',
+        '当天负载工时不超过该值时状态灯为 normal'
+    ),
+    (
+        'status_light_busy_max_hours',
+        '8',
+        '当天负载工时不超过该值时状态灯为 busy，超过则为 overloaded'
+    );
+
+-- 2. 按团队或角色覆盖全局阈值，团队优先于角色
+CREATE TABLE status_light_thresholds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    scope_type VARCHAR(20) NOT NULL, -- 'team', 'role'
+    scope_value VARCHAR(255) NOT NULL, -- 团队名或角色名
+    mode VARCHAR(20) NOT NULL DEFAULT 'percentage', -- 'percentage', 'hours'
+    normal_max FLOAT NOT NULL,
+    busy_max FLOAT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_status_light_thresholds_scope UNIQUE (scope_type, scope_value)
+);
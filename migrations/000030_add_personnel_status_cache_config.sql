@@ -0,0 +1,14 @@
+-- 000030_add_personnel_status_cache_config.sql
+
+-- 人员看板缓存时长：任务、请假、节假日变化时缓存会立即失效，该时长只用于兜底多实例之间的同步
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'personnel_status_cache_seconds',
+        '30',
+        '人员看板数据的缓存时长（秒），0 表示不缓存'
+    );
@@ -0,0 +1,19 @@
+-- 000031_add_pool_auto_assign_config.sql
+
+-- 任务池自动指派：任务进入任务池后，按推荐结果指派给得分最高的执行者
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'pool_auto_assign_enabled',
+        'false',
+        '是否自动将进入任务池的任务指派给推荐得分最高的执行者'
+    ),
+    (
+        'pool_auto_assign_min_score',
+        '60',
+        '自动指派要求的最低推荐得分（0-100），没有人达到时任务留在任务池'
+    );
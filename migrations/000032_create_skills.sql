@@ -0,0 +1,54 @@
+-- 000032_create_skills.sql
+
+-- 1. 技能标签库
+CREATE TABLE skills (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- 2. 用户技能：level 为本人自评 (1-5)，verified_level 为经理核实后的等级，核实后以核实等级为准
+CREATE TABLE user_skills (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    skill_id UUID NOT NULL REFERENCES skills (id) ON DELETE CASCADE,
+    level INT NOT NULL,
+    verified_level INT,
+    verified_by_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    verified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, skill_id)
+);
+
+-- 3. 任务类型要求的技能 (该类型任务的默认要求)
+CREATE TABLE task_type_skills (
+    task_type_id UUID NOT NULL REFERENCES task_types (id) ON DELETE CASCADE,
+    skill_id UUID NOT NULL REFERENCES skills (id) ON DELETE CASCADE,
+    min_level INT NOT NULL DEFAULT 1,
+    PRIMARY KEY (task_type_id, skill_id)
+);
+
+-- 4. 单个任务要求的技能，设置后覆盖任务类型的默认要求
+CREATE TABLE task_skills (
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    skill_id UUID NOT NULL REFERENCES skills (id) ON DELETE CASCADE,
+    min_level INT NOT NULL DEFAULT 1,
+    PRIMARY KEY (task_id, skill_id)
+);
+
+CREATE INDEX idx_user_skills_skill_id ON user_skills (skill_id);
+
+-- 5. 执行者任务池列表的技能匹配方式：'off' 不处理, 'sort' 匹配的任务排在前面, 'hide' 隐藏不匹配的任务
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'skill_match_mode',
+        'sort',
+        '执行者任务池的技能匹配方式：off（不处理）、sort（匹配的任务排在前面）、hide（隐藏技能不匹配的任务）'
+    );
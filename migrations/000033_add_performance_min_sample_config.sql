@@ -0,0 +1,14 @@
+-- 000033_add_performance_min_sample_config.sql
+
+-- 绩效统计的最低样本数：时间窗口内已评价任务少于该数量时不给出得分，避免个别任务左右结果
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'performance_min_sample_size',
+        '3',
+        '绩效统计窗口内至少需要多少个已评价任务才给出得分'
+    );
@@ -0,0 +1,25 @@
+-- 000034_create_generated_reports.sql
+
+-- 定期生成的绩效报告 (季度末自动为每位经理生成)，文件内容直接存入数据库，供之后下载
+CREATE TABLE generated_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    owner_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE, -- 报告生成给谁 (经理)
+    scope_type VARCHAR(20) NOT NULL, -- 'user', 'team', 'all'
+    scope_value VARCHAR(255) NOT NULL DEFAULT '', -- 用户ID或团队名
+    period_label VARCHAR(50) NOT NULL, -- 如 '2026-Q3'
+    period_start TIMESTAMPTZ NOT NULL,
+    period_end TIMESTAMPTZ NOT NULL,
+    format VARCHAR(10) NOT NULL, -- 'csv', 'xlsx', 'html'
+    file_name VARCHAR(255) NOT NULL,
+    content BYTEA NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT uq_generated_reports UNIQUE (
+        owner_id,
+        scope_type,
+        scope_value,
+        period_label,
+        format
+    )
+);
+
+CREATE INDEX idx_generated_reports_owner ON generated_reports (owner_id, created_at DESC);
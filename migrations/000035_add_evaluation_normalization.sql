@@ -0,0 +1,31 @@
+-- 000035_add_evaluation_normalization.sql
+
+-- 按评价人打分习惯校准后的评价分，与原始评价 (evaluation) 同一量纲，由校准作业重新计算
+ALTER TABLE tasks ADD COLUMN normalized_evaluation JSONB;
+
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'evaluation_normalization_method',
+        'zscore',
+        '评价分校准方法：zscore (按评价人的均值和标准差换算到全体分布) 或 percentile (按评价人内部的百分位映射到全体分布)'
+    ),
+    (
+        'evaluation_normalization_min_samples',
+        '5',
+        '评价人至少需要评价过多少个任务才对其评分进行校准，不足时校准分等于原始分'
+    ),
+    (
+        'evaluation_calibration_window_days',
+        '365',
+        '计算评价人打分习惯时使用最近多少天的评价，0 表示使用全部历史评价'
+    ),
+    (
+        'performance_score_basis',
+        'raw',
+        '绩效统计与人员看板默认使用的评价分：raw 原始分或 normalized 校准分'
+    );
@@ -0,0 +1,39 @@
+-- 000036_backfill_task_keys.sql
+
+-- 编号在任务首次确定任务类型时生成 (见 000019)，这里为引入编号之前就已审批通过的任务补充编号
+-- 仍在待审批 (或被驳回、等待重新提交) 的任务尚未确定类型，不在此补充，审批通过时再按任务类型的前缀生成
+-- 前缀规则与程序一致：任务类型的前缀优先，否则使用 'default_task_key_prefix'，编号接在各前缀当前序列之后
+
+-- 1. 按前缀分组，在现有序列值之后依次编号
+WITH keyless AS (
+    SELECT
+        t.id,
+        COALESCE(
+            tt.key_prefix,
+            NULLIF((SELECT config_value FROM system_configs WHERE config_key = 'default_task_key_prefix'), ''),
+            'TASK'
+        ) AS prefix
+    FROM tasks t
+    LEFT JOIN task_types tt ON tt.id = t.task_type_id
+    WHERE t.task_key IS NULL AND t.status NOT IN ('pending_review', 'rejected')
+),
+numbered AS (
+    SELECT
+        k.id,
+        k.prefix,
+        COALESCE(s.last_value, 0) + ROW_NUMBER() OVER (PARTITION BY k.prefix ORDER BY k.id) AS seq
+    FROM keyless k
+    LEFT JOIN task_key_sequences s ON s.prefix = k.prefix
+)
+UPDATE tasks
+SET task_key = numbered.prefix || '-' || numbered.seq
+FROM numbered
+WHERE tasks.id = numbered.id;
+
+-- 2. 将各前缀的序列推进到已使用的最大编号，避免之后生成重复编号
+INSERT INTO task_key_sequences (prefix, last_value)
+SELECT split_part(task_key, '-', 1), MAX(split_part(task_key, '-', 2)::BIGINT)
+FROM tasks
+WHERE task_key IS NOT NULL
+GROUP BY split_part(task_key, '-', 1)
+ON CONFLICT (prefix) DO UPDATE SET last_value = GREATEST(task_key_sequences.last_value, EXCLUDED.last_value);
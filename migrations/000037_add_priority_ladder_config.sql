@@ -0,0 +1,14 @@
+-- 000037_add_priority_ladder_config.sql
+
+-- 计划任务顺延 (roll_forward) 时逐级提升的优先级，从低到高，逗号分隔
+INSERT INTO
+    system_configs (
+        config_key,
+        config_value,
+        description
+    )
+VALUES (
+        'periodic_priority_ladder',
+        'low,medium,high,urgent',
+        '计划任务顺延旧任务时逐级提升的优先级，从低到高，逗号分隔；重叠策略为 roll_forward 的规则，其默认优先级必须在其中'
+    );
@@ -0,0 +1,19 @@
+-- 000038_unique_current_round_approval.sql
+
+-- 同一审批人在同一轮 (未作废) 中只能有一条批准记录，作为并发审批时的最后一道保障
+-- 1. 先作废历史上可能存在的重复批准，只保留每人最早的一条
+UPDATE task_approvals
+SET is_superseded = TRUE
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY task_id, approver_id ORDER BY created_at, id) AS rn
+        FROM task_approvals
+        WHERE decision = 'approved' AND NOT is_superseded
+    ) ranked
+    WHERE ranked.rn > 1
+);
+
+-- 2. 驳回记录不受限制 (驳回后保留在本轮，重新提交后同一审批人可以再次批准)
+CREATE UNIQUE INDEX uq_task_approvals_current_round
+    ON task_approvals (task_id, approver_id)
+    WHERE decision = 'approved' AND NOT is_superseded;
@@ -0,0 +1,19 @@
+-- 000039_unique_pending_transfer.sql
+
+-- 每个任务同时只能有一条待处理 (含待审批) 的转交，作为并发发起转交时的最后一道保障
+-- 1. 先取消历史上可能存在的重复待处理转交，只保留每个任务最早的一条
+UPDATE task_transfers
+SET status = 'cancelled'
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY task_id ORDER BY created_at, id) AS rn
+        FROM task_transfers
+        WHERE status IN ('pending', 'pending_approval')
+    ) ranked
+    WHERE ranked.rn > 1
+);
+
+-- 2. 已结束的转交 (accepted/rejected/cancelled/expired) 不受限制
+CREATE UNIQUE INDEX uq_task_transfers_pending
+    ON task_transfers (task_id)
+    WHERE status IN ('pending', 'pending_approval');
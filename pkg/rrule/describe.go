@@ -0,0 +1,101 @@
+// pkg/rrule/describe.go
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var freqUnits = map[string]string{"DAILY": "day", "WEEKLY": "week", "MONTHLY": "month", "YEARLY": "year"}
+
+// Describe 将规则转换为便于阅读的英文描述，如 "Every month on the last Friday at 09:00"
+func (r *Rule) Describe() string {
+	var b strings.Builder
+
+	unit := freqUnits[r.Freq]
+	if r.Interval > 1 {
+		fmt.Fprintf(&b, "Every %d %ss", r.Interval, unit)
+	} else {
+		b.WriteString("Every " + unit)
+	}
+
+	if len(r.ByMonth) > 0 {
+		var months []string
+		for _, m := range r.ByMonth {
+			months = append(months, time.Month(m).String())
+		}
+		b.WriteString(" in " + strings.Join(months, ", "))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, wd := range r.ByDay {
+			if wd.N == 0 {
+				days = append(days, wd.Weekday.String())
+			} else {
+				days = append(days, "the "+ordinal(wd.N)+" "+wd.Weekday.String())
+			}
+		}
+		b.WriteString(" on " + strings.Join(days, ", "))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, md := range r.ByMonthDay {
+			days = append(days, ordinal(md))
+		}
+		b.WriteString(" on the " + strings.Join(days, ", ") + " day")
+	}
+	if len(r.BySetPos) > 0 {
+		var positions []string
+		for _, pos := range r.BySetPos {
+			positions = append(positions, ordinal(pos))
+		}
+		b.WriteString(", only the " + strings.Join(positions, ", ") + " match in each " + unit)
+	}
+
+	hours := orDefault(r.ByHour, r.Dtstart.Hour())
+	minutes := orDefault(r.ByMinute, r.Dtstart.Minute())
+	if len(hours) == 1 && len(minutes) == 1 {
+		fmt.Fprintf(&b, " at %02d:%02d", hours[0], minutes[0])
+	} else {
+		fmt.Fprintf(&b, " at hours %s, minutes %s", joinInts(hours), joinInts(minutes))
+	}
+
+	if r.Count > 0 {
+		fmt.Fprintf(&b, ", %d times", r.Count)
+	}
+	if !r.Until.IsZero() {
+		b.WriteString(", until " + r.Until.Format("2006-01-02"))
+	}
+	return b.String()
+}
+
+// ordinal 将序号转换为英文序数词，负数表示倒数 (-1 -> last, -2 -> 2nd last)
+func ordinal(n int) string {
+	if n == -1 {
+		return "last"
+	}
+	if n < 0 {
+		return ordinal(-n) + " last"
+	}
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,454 @@
+// pkg/rrule/rrule.go
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule 是 RFC 5545 RRULE 的一个子集实现，可直接作为 cron.Schedule 使用 (实现了 Next 方法)
+// 支持：FREQ (DAILY/WEEKLY/MONTHLY/YEARLY)、INTERVAL、COUNT、UNTIL、BYMONTH、BYMONTHDAY、
+// BYDAY (可带序号，如 2TU、-1FR)、BYSETPOS、BYHOUR、BYMINUTE、BYSECOND，以及 DTSTART
+// 限制：BYDAY 的序号总是相对于月份计算 (YEARLY 时同样按月)，WKST 固定为周一，不支持 BYYEARDAY/BYWEEKNO
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByMonth    []int
+	ByMonthDay []int
+	ByDay      []WeekdayNum
+	BySetPos   []int
+	ByHour     []int
+	ByMinute   []int
+	BySecond   []int
+	Dtstart    time.Time
+}
+
+// WeekdayNum 是 BYDAY 中的一项，N 为 0 表示不限序号 (每个该星期几)
+type WeekdayNum struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// maxPeriods 查找下一次触发时最多检查的周期数，防止永远不会命中的规则 (如 2月30日) 死循环
+const maxPeriods = 5000
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// Parse 解析 RRULE 文本，可以是单独的 "FREQ=...;..."、"RRULE:FREQ=..."，也可以带有 "DTSTART..." 行
+// 没有 DTSTART 时使用 defaultStart；不带时区的时间按 loc 解释
+func Parse(text string, defaultStart time.Time, loc *time.Location) (*Rule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	rule := &Rule{Interval: 1, Dtstart: defaultStart.In(loc).Truncate(time.Second)}
+
+	var rulePart string
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "DTSTART"):
+			start, err := parseDtstart(line, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Dtstart = start
+		case strings.HasPrefix(upper, "RRULE:"):
+			rulePart = line[len("RRULE:"):]
+		default:
+			rulePart = line
+		}
+	}
+	if rulePart == "" {
+		return nil, errors.New("rrule: missing rule")
+	}
+
+	for _, part := range strings.Split(rulePart, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("rrule: invalid part %q", part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch value {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = value
+			default:
+				return nil, fmt.Errorf("rrule: unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("rrule: INTERVAL must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("rrule: COUNT must be positive")
+			}
+		case "UNTIL":
+			rule.Until, err = parseDateTime(value, loc)
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(value, 1, 12, false)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(value, 1, 31, true)
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(value, 1, 366, true)
+		case "BYHOUR":
+			rule.ByHour, err = parseIntList(value, 0, 23, false)
+		case "BYMINUTE":
+			rule.ByMinute, err = parseIntList(value, 0, 59, false)
+		case "BYSECOND":
+			rule.BySecond, err = parseIntList(value, 0, 59, false)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		case "WKST":
+			// 周起始日固定为周一
+		default:
+			return nil, fmt.Errorf("rrule: unsupported part %q", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rule.Freq == "" {
+		return nil, errors.New("rrule: FREQ is required")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, errors.New("rrule: COUNT and UNTIL cannot be used together")
+	}
+	return rule, nil
+}
+
+// Next 返回严格晚于 t 的下一次触发时间，没有更多触发时返回零值
+func (r *Rule) Next(t time.Time) time.Time {
+	loc := r.Dtstart.Location()
+	t = t.In(loc)
+
+	// 没有 COUNT 限制时可以直接跳到 t 附近的周期，否则必须从 DTSTART 开始计数
+	first := 0
+	if r.Count == 0 {
+		first = r.periodIndexNear(t)
+	}
+
+	seen := 0
+	for k := first; k < first+maxPeriods; k++ {
+		for _, occurrence := range r.occurrencesInPeriod(k) {
+			if occurrence.Before(r.Dtstart) {
+				continue
+			}
+			seen++
+			if r.Count > 0 && seen > r.Count {
+				return time.Time{}
+			}
+			if !r.Until.IsZero() && occurrence.After(r.Until) {
+				return time.Time{}
+			}
+			if occurrence.After(t) {
+				return occurrence
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// periodIndexNear 估算包含 t 的周期序号 (向前留一个周期的余量)
+func (r *Rule) periodIndexNear(t time.Time) int {
+	if !t.After(r.Dtstart) {
+		return 0
+	}
+	var elapsed int
+	switch r.Freq {
+	case "DAILY":
+		elapsed = int(dateOf(t).Sub(dateOf(r.Dtstart)).Hours() / 24)
+	case "WEEKLY":
+		elapsed = int(weekStart(t).Sub(weekStart(r.Dtstart)).Hours() / 24 / 7)
+	case "MONTHLY":
+		elapsed = (t.Year()-r.Dtstart.Year())*12 + int(t.Month()-r.Dtstart.Month())
+	case "YEARLY":
+		elapsed = t.Year() - r.Dtstart.Year()
+	}
+	index := elapsed/r.Interval - 1
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+// occurrencesInPeriod 计算第 k 个周期内的所有触发时间 (已排序并应用 BYSETPOS)
+func (r *Rule) occurrencesInPeriod(k int) []time.Time {
+	start := r.Dtstart
+	loc := start.Location()
+
+	var days []time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := dateOf(start).AddDate(0, 0, k*r.Interval)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			days = append(days, day)
+		}
+	case "WEEKLY":
+		monday := weekStart(start).AddDate(0, 0, 7*k*r.Interval)
+		for i := 0; i < 7; i++ {
+			day := monday.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchesMonth(day) && r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case "MONTHLY":
+		month := time.Date(start.Year(), start.Month()+time.Month(k*r.Interval), 1, 0, 0, 0, 0, loc)
+		if r.matchesMonth(month) {
+			days = r.daysInMonth(month)
+		}
+	case "YEARLY":
+		year := start.Year() + k*r.Interval
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []int{int(start.Month())}
+		}
+		for _, m := range months {
+			days = append(days, r.daysInMonth(time.Date(year, time.Month(m), 1, 0, 0, 0, 0, loc))...)
+		}
+	}
+
+	hours := orDefault(r.ByHour, start.Hour())
+	minutes := orDefault(r.ByMinute, start.Minute())
+	seconds := orDefault(r.BySecond, start.Second())
+
+	var occurrences []time.Time
+	for _, day := range days {
+		for _, h := range hours {
+			for _, m := range minutes {
+				for _, s := range seconds {
+					occurrences = append(occurrences, time.Date(day.Year(), day.Month(), day.Day(), h, m, s, 0, loc))
+				}
+			}
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+
+	if len(r.BySetPos) == 0 {
+		return occurrences
+	}
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		idx := pos - 1
+		if pos < 0 {
+			idx = len(occurrences) + pos
+		}
+		if idx >= 0 && idx < len(occurrences) {
+			selected = append(selected, occurrences[idx])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return dedupe(selected)
+}
+
+// daysInMonth 计算月份内符合 BYMONTHDAY / BYDAY 的日期；两者都没有时取 DTSTART 的日
+func (r *Rule) daysInMonth(month time.Time) []time.Time {
+	lastDay := month.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	for d := 1; d <= lastDay; d++ {
+		day := time.Date(month.Year(), month.Month(), d, 0, 0, 0, 0, month.Location())
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if d == r.Dtstart.Day() {
+				days = append(days, day)
+			}
+			continue
+		}
+		if r.matchesMonthDay(day) && r.matchesWeekdayInMonth(day, lastDay) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *Rule) matchesMonth(day time.Time) bool {
+	return len(r.ByMonth) == 0 || containsInt(r.ByMonth, int(day.Month()))
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, md := range r.ByMonthDay {
+		if md == day.Day() || (md < 0 && lastDay+md+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday 只比较星期几，忽略序号 (用于 DAILY / WEEKLY)
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekdayInMonth 比较星期几及其在月份中的序号 (如本月第2个周二、最后一个周五)
+func (r *Rule) matchesWeekdayInMonth(day time.Time, lastDay int) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	nth := (day.Day()-1)/7 + 1
+	nthFromEnd := -((lastDay-day.Day())/7 + 1)
+	for _, wd := range r.ByDay {
+		if wd.Weekday != day.Weekday() {
+			continue
+		}
+		if wd.N == 0 || wd.N == nth || wd.N == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// --- 解析辅助函数 ---
+
+func parseDtstart(line string, loc *time.Location) (time.Time, error) {
+	idx := strings.LastIndex(line, ":")
+	if idx < 0 {
+		return time.Time{}, errors.New("rrule: invalid DTSTART")
+	}
+	params, value := line[:idx], line[idx+1:]
+	if i := strings.Index(strings.ToUpper(params), "TZID="); i >= 0 {
+		tz, err := time.LoadLocation(params[i+len("TZID="):])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("rrule: unknown TZID in DTSTART: %v", err)
+		}
+		loc = tz
+	}
+	start, err := parseDateTime(value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.In(loc), nil
+}
+
+func parseDateTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	if len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, loc)
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("rrule: invalid date-time %q", value)
+	}
+	return t, nil
+}
+
+func parseIntList(value string, min, max int, allowNegative bool) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, fmt.Errorf("rrule: invalid number %q", item)
+		}
+		abs := n
+		if allowNegative && n < 0 {
+			abs = -n
+		}
+		if abs < min || abs > max {
+			return nil, fmt.Errorf("rrule: value %d out of range", n)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var result []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, fmt.Errorf("rrule: invalid BYDAY %q", item)
+		}
+		weekday, ok := weekdayCodes[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("rrule: invalid BYDAY %q", item)
+		}
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 5 || n < -5 {
+				return nil, fmt.Errorf("rrule: invalid BYDAY %q", item)
+			}
+		}
+		result = append(result, WeekdayNum{N: n, Weekday: weekday})
+	}
+	return result, nil
+}
+
+// --- 通用辅助函数 ---
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekStart 返回 t 所在周的周一零点
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return dateOf(t).AddDate(0, 0, -offset)
+}
+
+func orDefault(values []int, def int) []int {
+	if len(values) == 0 {
+		return []int{def}
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+func containsInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(times []time.Time) []time.Time {
+	var result []time.Time
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			result = append(result, t)
+		}
+	}
+	return result
+}
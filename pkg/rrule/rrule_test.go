@@ -0,0 +1,100 @@
+// pkg/rrule/rrule_test.go
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRuleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	utc := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+
+	cases := []struct {
+		name string
+		text string
+		from time.Time
+		want []time.Time // 零值表示规则已耗尽
+	}{
+		{
+			name: "每月第二个周二",
+			text: "DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=2TU",
+			from: utc(2026, 1, 1, 0, 0),
+			want: []time.Time{utc(2026, 1, 13, 9, 0), utc(2026, 2, 10, 9, 0), utc(2026, 3, 10, 9, 0)},
+		},
+		{
+			name: "从周期中间继续计算第二个周二",
+			text: "DTSTART:20260101T090000\nRRULE:FREQ=MONTHLY;BYDAY=2TU",
+			from: utc(2026, 6, 20, 0, 0),
+			want: []time.Time{utc(2026, 7, 14, 9, 0), utc(2026, 8, 11, 9, 0)},
+		},
+		{
+			name: "每月最后一个工作日",
+			text: "DTSTART:20260101T170000\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			from: utc(2026, 1, 1, 0, 0),
+			want: []time.Time{utc(2026, 1, 30, 17, 0), utc(2026, 2, 27, 17, 0), utc(2026, 3, 31, 17, 0)},
+		},
+		{
+			name: "COUNT 用尽后不再产生",
+			text: "DTSTART:20260105T080000\nRRULE:FREQ=DAILY;COUNT=3",
+			from: utc(2026, 1, 1, 0, 0),
+			want: []time.Time{utc(2026, 1, 5, 8, 0), utc(2026, 1, 6, 8, 0), utc(2026, 1, 7, 8, 0), {}},
+		},
+		{
+			name: "UNTIL 之后不再产生",
+			text: "DTSTART:20260105T100000\nRRULE:FREQ=WEEKLY;BYDAY=MO;UNTIL=20260119T235959Z",
+			from: utc(2026, 1, 1, 0, 0),
+			want: []time.Time{utc(2026, 1, 5, 10, 0), utc(2026, 1, 12, 10, 0), utc(2026, 1, 19, 10, 0), {}},
+		},
+		{
+			name: "INTERVAL 隔周",
+			text: "DTSTART:20260107T100000\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=WE",
+			from: utc(2026, 1, 1, 0, 0),
+			want: []time.Time{utc(2026, 1, 7, 10, 0), utc(2026, 1, 21, 10, 0), utc(2026, 2, 4, 10, 0)},
+		},
+		{
+			name: "夏令时切换前后保持本地时间",
+			text: "DTSTART;TZID=America/New_York:20260306T090000\nRRULE:FREQ=DAILY",
+			from: time.Date(2026, 3, 6, 12, 0, 0, 0, newYork),
+			want: []time.Time{
+				time.Date(2026, 3, 7, 9, 0, 0, 0, newYork),
+				time.Date(2026, 3, 8, 9, 0, 0, 0, newYork),
+				time.Date(2026, 3, 9, 9, 0, 0, 0, newYork),
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, err := Parse(tc.text, time.Time{}, time.UTC)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tc.text, err)
+			}
+			cursor := tc.from
+			for i, want := range tc.want {
+				got := rule.Next(cursor)
+				if want.IsZero() {
+					if !got.IsZero() {
+						t.Fatalf("occurrence %d: got %v, want exhausted", i, got)
+					}
+					return
+				}
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d: got %v, want %v", i, got, want)
+				}
+				cursor = got
+			}
+		})
+	}
+}
+
+func TestParseRejectsCountWithUntil(t *testing.T) {
+	if _, err := Parse("FREQ=DAILY;COUNT=3;UNTIL=20260119T000000Z", time.Now(), time.UTC); err == nil {
+		t.Fatal("expected error for COUNT combined with UNTIL")
+	}
+}
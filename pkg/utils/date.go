@@ -2,6 +2,7 @@
 package utils
 
 import (
+	"errors"
 	"gotasksys/internal/repository"
 	"time"
 
@@ -52,3 +53,104 @@ func CalculateAvailableWorkingDays(userID uuid.UUID, start, end time.Time) (int,
 
 	return availableDays, nil
 }
+
+// maxWorkingDaySearchDays 查找相邻工作日时最多向前/向后搜索的天数 (足以覆盖最长的法定长假)
+const maxWorkingDaySearchDays = 60
+
+// IsHoliday 判断某一天是否为全局法定节假日
+func IsHoliday(day time.Time) (bool, error) {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	holidays, err := repository.GetHolidaysInRange(date, date)
+	if err != nil {
+		return false, err
+	}
+	return holidays[date.Format("2006-01-02")], nil
+}
+
+// IsUserOnLeave 判断某个用户在某一天是否请假
+func IsUserOnLeave(userID uuid.UUID, day time.Time) (bool, error) {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	leaves, err := repository.ListLeaveDatesInRange(userID, date, date)
+	if err != nil {
+		return false, err
+	}
+	return leaves[date.Format("2006-01-02")], nil
+}
+
+// ShiftToWorkingDay 从 day 的下一天 (direction > 0) 或前一天 (direction < 0) 开始，逐日查找第一个工作日 (非周末、非全局节假日)
+// 返回的时间保留 day 原有的时分秒
+func ShiftToWorkingDay(day time.Time, direction int) (time.Time, error) {
+	step := 1
+	if direction < 0 {
+		step = -1
+	}
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	rangeStart, rangeEnd := date, date.AddDate(0, 0, maxWorkingDaySearchDays)
+	if step < 0 {
+		rangeStart, rangeEnd = date.AddDate(0, 0, -maxWorkingDaySearchDays), date
+	}
+	holidays, err := repository.GetHolidaysInRange(rangeStart, rangeEnd)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for i := 1; i <= maxWorkingDaySearchDays; i++ {
+		candidate := day.AddDate(0, 0, i*step)
+		if isWorkingDate(candidate, holidays) {
+			return candidate, nil
+		}
+	}
+	return time.Time{}, errors.New("no working day found within search range")
+}
+
+// AddWorkingDays 返回 day 之后第 n 个工作日 (n 为 0 时返回 day 本身)，保留 day 原有的时分秒
+func AddWorkingDays(day time.Time, n int) (time.Time, error) {
+	if n <= 0 {
+		return day, nil
+	}
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	searchDays := n*2 + maxWorkingDaySearchDays
+	holidays, err := repository.GetHolidaysInRange(date, date.AddDate(0, 0, searchDays))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	found := 0
+	for i := 1; i <= searchDays; i++ {
+		candidate := day.AddDate(0, 0, i)
+		if !isWorkingDate(candidate, holidays) {
+			continue
+		}
+		found++
+		if found == n {
+			return candidate, nil
+		}
+	}
+	return time.Time{}, errors.New("no working day found within search range")
+}
+
+// LastWorkingDayBetween 返回 [start, end] 范围内的最后一个工作日；范围内没有工作日时返回 end
+func LastWorkingDayBetween(start, end time.Time) (time.Time, error) {
+	startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	holidays, err := repository.GetHolidaysInRange(startDate, endDate)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for candidate := end; !candidate.Before(startDate); candidate = candidate.AddDate(0, 0, -1) {
+		if isWorkingDate(candidate, holidays) {
+			return candidate, nil
+		}
+	}
+	return end, nil
+}
+
+// isWorkingDate 判断某天是否为工作日 (非周末、非全局节假日)
+func isWorkingDate(day time.Time, holidays map[string]bool) bool {
+	weekday := day.Weekday()
+	if weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	return !holidays[day.Format("2006-01-02")]
+}
@@ -0,0 +1,208 @@
+// pkg/xlsx/xlsx.go
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Workbook 一个只写的最小化 XLSX 工作簿，支持多个工作表、字符串/数字单元格和加粗的表头行
+// 字符串使用内联字符串 (inlineStr)，不生成共享字符串表
+type Workbook struct {
+	sheets []*Sheet
+}
+
+// Sheet 一个工作表，按行追加数据
+type Sheet struct {
+	name string
+	rows []row
+}
+
+type row struct {
+	cells []interface{}
+	bold  bool
+}
+
+// NewWorkbook 创建一个空工作簿
+func NewWorkbook() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet 添加一个工作表，名称中 Excel 不允许的字符会被替换，超过 31 个字符时截断
+func (w *Workbook) AddSheet(name string) *Sheet {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = fmt.Sprintf("Sheet%d", len(w.sheets)+1)
+	}
+	sheet := &Sheet{name: name}
+	w.sheets = append(w.sheets, sheet)
+	return sheet
+}
+
+// AddHeader 追加一行加粗的表头
+func (s *Sheet) AddHeader(cells ...interface{}) {
+	s.rows = append(s.rows, row{cells: cells, bold: true})
+}
+
+// AddRow 追加一行数据，单元格可以是字符串、整数或浮点数，nil 表示空单元格
+func (s *Sheet) AddRow(cells ...interface{}) {
+	s.rows = append(s.rows, row{cells: cells})
+}
+
+// Write 将工作簿写为 XLSX 格式
+func (w *Workbook) Write(out io.Writer) error {
+	if len(w.sheets) == 0 {
+		w.AddSheet("Sheet1")
+	}
+	zw := zip.NewWriter(out)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", w.workbookXML()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", stylesXML},
+	}
+	for i, sheet := range w.sheets {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Bytes 将工作簿写为 XLSX 格式并返回字节
+func (w *Workbook) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+const rootRels = xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// stylesXML 两种单元格样式：0 为默认，1 为加粗
+const stylesXML = xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+func (w *Workbook) contentTypes() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+func (w *Workbook) workbookXML() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, sheet := range w.sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheet.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+func (w *Workbook) workbookRels() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+func (s *Sheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, rw := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		style := ""
+		if rw.bold {
+			style = ` s="1"`
+		}
+		for c, cell := range rw.cells {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			switch v := cell.(type) {
+			case nil:
+				continue
+			case int:
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+			case int64:
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+			case float64:
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(fmt.Sprint(v)))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnName 将从 0 开始的列序号转换为 Excel 列名 (0 -> A, 26 -> AA)
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,125 @@
+// pkg/xlsx/xlsx_test.go
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+type sheetDoc struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string `xml:"r,attr"`
+			Type   string `xml:"t,attr"`
+			Style  string `xml:"s,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type workbookDoc struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+func readZipFile(t *testing.T, zr *zip.Reader, name string) []byte {
+	t.Helper()
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return data
+	}
+	t.Fatalf("missing %s in archive", name)
+	return nil
+}
+
+func TestWorkbookRoundTrip(t *testing.T) {
+	wb := NewWorkbook()
+	summary := wb.AddSheet("Q3/2026 汇总")
+	summary.AddHeader("Name", "Effort", "Score")
+	summary.AddRow("<Alice & Bob>", 5, 87.5)
+	summary.AddRow("Carol", nil, int64(3))
+	wb.AddSheet("Details")
+
+	data, err := wb.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet2.xml"} {
+		var v struct{}
+		if err := xml.Unmarshal(readZipFile(t, zr, name), &v); err != nil {
+			t.Fatalf("%s is not well-formed XML: %v", name, err)
+		}
+	}
+
+	var book workbookDoc
+	if err := xml.Unmarshal(readZipFile(t, zr, "xl/workbook.xml"), &book); err != nil {
+		t.Fatalf("unmarshal workbook.xml: %v", err)
+	}
+	if len(book.Sheets) != 2 || book.Sheets[0].Name != "Q3_2026 汇总" || book.Sheets[1].Name != "Details" {
+		t.Fatalf("unexpected sheets: %+v", book.Sheets)
+	}
+
+	var sheet sheetDoc
+	if err := xml.Unmarshal(readZipFile(t, zr, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatalf("unmarshal sheet1.xml: %v", err)
+	}
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(sheet.Rows))
+	}
+
+	header := sheet.Rows[0].Cells
+	if len(header) != 3 || header[0].Inline != "Name" || header[0].Style != "1" || header[0].Type != "inlineStr" {
+		t.Fatalf("unexpected header row: %+v", header)
+	}
+
+	first := sheet.Rows[1].Cells
+	if len(first) != 3 {
+		t.Fatalf("got %d cells in row 2, want 3", len(first))
+	}
+	if first[0].Ref != "A2" || first[0].Inline != "<Alice & Bob>" || first[0].Style != "" {
+		t.Errorf("unexpected text cell: %+v", first[0])
+	}
+	if first[1].Ref != "B2" || first[1].Type != "" || first[1].Value != "5" {
+		t.Errorf("unexpected int cell: %+v", first[1])
+	}
+	if first[2].Ref != "C2" || first[2].Value != "87.5" {
+		t.Errorf("unexpected float cell: %+v", first[2])
+	}
+
+	// nil 单元格不输出，但后续单元格保持原列位置
+	second := sheet.Rows[2].Cells
+	if len(second) != 2 || second[0].Ref != "A3" || second[1].Ref != "C3" || second[1].Value != "3" {
+		t.Errorf("unexpected row 3: %+v", second)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, want := range cases {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}